@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/policy"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// approval is the user's decision on a proposed agent step
+type approval struct {
+	command string
+	run     bool
+	auto    bool
+	edited  bool
+	quit    bool
+}
+
+// runningStep cancels the agent step that is running, if any, so Ctrl-C
+// stops the step instead of GO-TERM
+var runningStep atomic.Pointer[context.CancelFunc]
+
+// handleAgentCommand runs a goal or manages saved agent transcripts
+func handleAgentCommand(args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: agent [--steps N] <goal> | agent list | agent show|replay <name> | agent export <name> [file]")
+	}
+
+	// Subcommands only apply when they name a saved transcript, so goals
+	// such as "show disk usage" still reach the model
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		return listAgentSessions()
+	case (args[0] == "show" || args[0] == "replay") && len(args) == 2,
+		args[0] == "export" && (len(args) == 2 || len(args) == 3):
+		if session, err := ai.LoadAgentSession(args[1]); err == nil {
+			switch args[0] {
+			case "show":
+				fmt.Println(session.Markdown())
+			case "replay":
+				replayAgentSession(session, line, history)
+			case "export":
+				return exportAgentSession(session, args[2:])
+			}
+			return nil
+		}
+	}
+
+	maxSteps := config.GetConfig().Agent.MaxSteps
+	if args[0] == "--steps" {
+		if len(args) < 2 {
+			return fmt.Errorf("--steps requires a number")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid step limit: %s", args[1])
+		}
+		maxSteps = n
+		args = args[2:]
+	}
+
+	flags, rest, err := parseAIFlags(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return fmt.Errorf("agent requires a goal")
+	}
+
+	ctx, _ := aiContext(flags, spinner)
+	runAgent(ctx, strings.Join(rest, " "), maxSteps, line, history, spinner)
+	return nil
+}
+
+// runAgent works toward a goal one approved command at a time
+func runAgent(ctx context.Context, goal string, maxSteps int, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	warnColor := color.New(color.FgYellow, color.Bold).SprintFunc()
+
+	session := ai.NewAgentSession(goal)
+	fmt.Println(headerColor("🤖 Agent:"), goal, hintColor(fmt.Sprintf("(up to %d steps)", maxSteps)))
+
+	finished := false
+	for step := 1; step <= maxSteps; step++ {
+		spinner.Start(color.New(color.FgCyan).Sprintf("✨ Planning step %d...", step))
+		next, err := session.Next(ctx)
+		spinner.Stop()
+
+		if err != nil {
+			printAIError("Agent stopped:", err)
+			finished = true
+			break
+		}
+
+		if next.Done {
+			session.Finish(next.Summary)
+			fmt.Println(successColor("✓ Done"))
+			if next.Summary != "" {
+				printBox(next.Summary)
+			}
+			finished = true
+			break
+		}
+
+		if len(next.Plan) > 0 {
+			fmt.Println(hintColor("Plan: " + strings.Join(next.Plan, " → ")))
+		}
+		fmt.Printf("%s %s\n", headerColor(fmt.Sprintf("Step %d:", step)), next.Thought)
+
+		decision := approveStep(next.Command, line)
+		if decision.quit {
+			session.Record(ai.AgentRecord{Thought: next.Thought, Command: next.Command, Outcome: ai.StepSkipped})
+			fmt.Println(warnColor("Agent stopped by user."))
+			finished = true
+			break
+		}
+		if !decision.run {
+			session.Record(ai.AgentRecord{Thought: next.Thought, Command: next.Command, Outcome: ai.StepSkipped})
+			continue
+		}
+
+		appendHistory(line, decision.command)
+		history.Add(decision.command)
+
+		result := runStep(ctx, decision.command)
+		status := successColor(fmt.Sprintf("exit %d", result.ExitCode))
+		if result.ExitCode != 0 {
+			status = color.New(color.FgRed, color.Bold).Sprintf("exit %d", result.ExitCode)
+		}
+		fmt.Println(status, hintColor(result.Duration.Round(time.Millisecond).String()))
+
+		session.Record(ai.AgentRecord{
+			Thought:      next.Thought,
+			Command:      decision.command,
+			Outcome:      ai.StepRan,
+			AutoApproved: decision.auto,
+			Edited:       decision.edited,
+			Stdout:       result.Stdout,
+			Stderr:       result.Stderr,
+			ExitCode:     result.ExitCode,
+			Duration:     result.Duration,
+		})
+	}
+
+	if !finished {
+		fmt.Println(warnColor(fmt.Sprintf("Step limit of %d reached.", maxSteps)), hintColor("Use agent --steps N to allow more."))
+	}
+
+	if len(session.Steps) == 0 {
+		return
+	}
+	if err := ai.SaveAgentSession(session); err != nil {
+		fmt.Println(color.New(color.FgRed, color.Bold).Sprint("Error saving transcript:"), err)
+		return
+	}
+	fmt.Println(hintColor(fmt.Sprintf("Transcript saved as %s (agent show|replay|export %s)", session.Name, session.Name)))
+}
+
+// runStep runs an approved command, stopping it on Ctrl-C or once it has
+// run for agent.step_timeout seconds
+func runStep(ctx context.Context, command string) terminal.CaptureResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	runningStep.Store(&cancel)
+	defer runningStep.Store(nil)
+
+	cfg := config.GetConfig().Agent
+	return terminal.RunCaptured(ctx, command, cfg.OutputLimit, time.Duration(cfg.StepTimeout)*time.Second)
+}
+
+// approveStep shows a proposed command and asks whether to run it.
+// Read-only commands are approved automatically when the policy allows it.
+func approveStep(command string, line *liner.State) approval {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	keyColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	fmt.Println("   " + color.New(color.FgHiCyan, color.Bold).Sprint(command))
+
+	if config.GetConfig().Agent.AutoApproveReadOnly && policy.IsReadOnly(command) {
+		fmt.Println("   " + hintColor("✓ auto-approved (read-only)"))
+		return approval{command: command, run: true, auto: true}
+	}
+
+	assessment := policy.Assess(command)
+	for _, reason := range assessment.Reasons {
+		fmt.Println("   " + color.New(color.FgYellow).Sprint("⚠ ") + hintColor(reason))
+	}
+
+	fmt.Print(keyColor("[y]") + "es  " + keyColor("[e]") + "dit  " + keyColor("[s]") + "kip  " + keyColor("[q]") + "uit ")
+	key, err := ui.ReadKey()
+	fmt.Println()
+	if err != nil {
+		// Nobody to approve the step
+		return approval{quit: true}
+	}
+
+	decision := approval{command: command}
+	switch key {
+	case "y", "Y", ui.KeyEnter:
+		decision.run = true
+	case "e", "E":
+		edited, err := line.PromptWithSuggestion(color.New(color.FgHiMagenta, color.Bold).Sprint("edit ❯ "), command, -1)
+		edited = strings.TrimSpace(edited)
+		if err != nil || edited == "" {
+			return decision
+		}
+		decision.command, decision.run, decision.edited = edited, true, edited != command
+		assessment = policy.Assess(edited)
+	case "q", "Q", ui.KeyEscape, ui.KeyCtrlC:
+		decision.quit = true
+		return decision
+	default:
+		return decision
+	}
+
+	if assessment.Risk == policy.RiskHigh {
+		fmt.Println(errorColor("⚠ This command is high risk:"), strings.Join(assessment.Reasons, "; "))
+		answer, err := line.Prompt("Type 'yes' to run it anyway: ")
+		if err != nil || strings.TrimSpace(answer) != "yes" {
+			decision.run = false
+		}
+	}
+
+	return decision
+}
+
+// replayAgentSession re-runs the commands of a transcript without the model,
+// asking for approval again at every step
+func replayAgentSession(session *ai.AgentSession, line *liner.State, history *terminal.History) {
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Println(headerColor("🔁 Replaying:"), session.Goal)
+
+	for i, step := range session.Steps {
+		if step.Outcome != ai.StepRan {
+			continue
+		}
+
+		fmt.Printf("%s %s\n", headerColor(fmt.Sprintf("Step %d:", i+1)), step.Thought)
+		decision := approveStep(step.Command, line)
+		if decision.quit {
+			fmt.Println(color.New(color.FgYellow, color.Bold).Sprint("Replay stopped."))
+			return
+		}
+		if !decision.run {
+			continue
+		}
+
+		appendHistory(line, decision.command)
+		history.Add(decision.command)
+
+		result := runStep(context.Background(), decision.command)
+		note := ""
+		if result.ExitCode != step.ExitCode {
+			note = fmt.Sprintf(" (was exit %d)", step.ExitCode)
+		}
+		fmt.Println(hintColor(fmt.Sprintf("exit %d%s", result.ExitCode, note)))
+	}
+}
+
+// exportAgentSession writes a transcript as Markdown, to <name>.md by default
+func exportAgentSession(session *ai.AgentSession, args []string) error {
+	path := session.Name + ".md"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	if err := os.WriteFile(path, []byte(session.Markdown()), 0644); err != nil {
+		return err
+	}
+	fmt.Println(color.New(color.FgGreen, color.Bold).Sprint("✓ Exported transcript to"), path)
+	return nil
+}
+
+// listAgentSessions prints saved transcripts
+func listAgentSessions() error {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	infos, err := ai.ListAgentSessions()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		fmt.Println(hintColor("No saved agent transcripts"))
+		return nil
+	}
+
+	for _, info := range infos {
+		state := "stopped"
+		if info.Done {
+			state = "done"
+		}
+		fmt.Printf("  %s %s %s\n", info.Name, info.Goal,
+			hintColor(fmt.Sprintf("(%d steps, %s, %s)", info.Steps, state, info.Updated.Format("2006-01-02 15:04"))))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// handleAICommand manages AI housekeeping such as the response cache
+func handleAICommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("ai command requires a subcommand (cache, usage)")
+	}
+
+	switch args[0] {
+	case "cache":
+		return handleAICacheCommand(args[1:])
+	case "usage":
+		return handleAIUsageCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown ai subcommand: %s", args[0])
+	}
+}
+
+// handleAICacheCommand shows or clears the on-disk response cache
+func handleAICacheCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("ai cache requires a subcommand (stats, clear)")
+	}
+
+	keyColor := color.New(color.FgCyan).SprintFunc()
+
+	switch args[0] {
+	case "stats":
+		stats, err := ai.GetCacheStats()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s %d (%d expired)\n", keyColor("entries:"), stats.Entries, stats.Expired)
+		fmt.Printf("  %s %.1f KB\n", keyColor("size:   "), float64(stats.Bytes)/1024)
+		if stats.Entries > 0 {
+			fmt.Printf("  %s %s\n", keyColor("oldest: "), stats.Oldest.Format("2006-01-02 15:04"))
+			fmt.Printf("  %s %s\n", keyColor("newest: "), stats.Newest.Format("2006-01-02 15:04"))
+		}
+
+	case "clear":
+		removed, err := ai.ClearCache()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cached responses\n", removed)
+
+	default:
+		return fmt.Errorf("unknown ai cache subcommand: %s", args[0])
+	}
+
+	return nil
+}
+
+// handleAIUsageCommand prints token usage and estimated cost, grouped by
+// command, model or day
+func handleAIUsageCommand(args []string) error {
+	since := 7 * 24 * time.Hour
+	by := "command"
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--since":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--since requires a duration such as 7d or 12h")
+			}
+			i++
+			d, err := parseSince(args[i])
+			if err != nil {
+				return err
+			}
+			since = d
+		case "--by":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--by requires command, model or day")
+			}
+			i++
+			by = args[i]
+		default:
+			return fmt.Errorf("usage: ai usage [--since 7d] [--by command|model|day]")
+		}
+	}
+
+	rows, err := ai.UsageReport(time.Now().Add(-since), by)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("No AI usage recorded in that period")
+		return nil
+	}
+
+	headerColor := color.New(color.FgCyan, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Println(headerColor(fmt.Sprintf("%-18s %6s %10s %10s %10s %10s %8s", by, "calls", "prompt", "output", "total", "cost", "latency")))
+	var total ai.UsageRow
+	for _, row := range rows {
+		fmt.Printf("%-18s %6d %10d %10d %10d %10s %8s\n", row.Key, row.Calls, row.PromptTokens,
+			row.CandidateTokens, row.TotalTokens, fmt.Sprintf("$%.4f", row.Cost), row.AvgLatency.Round(time.Millisecond))
+		total.Calls += row.Calls
+		total.PromptTokens += row.PromptTokens
+		total.CandidateTokens += row.CandidateTokens
+		total.TotalTokens += row.TotalTokens
+		total.Cost += row.Cost
+	}
+	fmt.Printf("%-18s %6d %10d %10d %10d %10s\n", "total", total.Calls, total.PromptTokens,
+		total.CandidateTokens, total.TotalTokens, fmt.Sprintf("$%.4f", total.Cost))
+	fmt.Println(hintColor("Costs are estimates from the usage.prices table in ~/.goterm.json"))
+
+	return nil
+}
+
+// parseSince reads durations like "7d", "12h" or "30m"
+func parseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return d, nil
+}
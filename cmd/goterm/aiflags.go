@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/auth"
+	"github/0PrashantYadav0/GO-TERM/internal/redact"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// aiFlags holds the options accepted by the AI builtins
+type aiFlags struct {
+	candidates   int
+	showRedacted bool
+	noCache      bool
+	refresh      bool
+}
+
+// aiContext builds the request context for an AI builtin from its flags. The
+// returned info is filled in once the request has been answered.
+func aiContext(flags aiFlags, spinner *ui.Spinner) (context.Context, *ai.CallInfo) {
+	info := &ai.CallInfo{}
+	opts := ai.RequestOptions{
+		NoCache: flags.noCache,
+		Refresh: flags.refresh,
+		Info:    info,
+	}
+
+	if flags.showRedacted {
+		opts.Preview = func(payload string, findings []redact.Finding) bool {
+			// The spinner is running while the request is prepared
+			spinner.Stop()
+
+			headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+			hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+			fmt.Println(headerColor("📤 Request preview"), hintColor("(exactly what will be sent)"))
+			fmt.Println(hintColor(payload))
+			if len(findings) > 0 {
+				fmt.Println(color.New(color.FgYellow, color.Bold).Sprint("🔒 Redacted: ") + redact.Summary(findings))
+			} else {
+				fmt.Println(hintColor("🔒 Nothing needed redacting"))
+			}
+
+			return confirmSend(spinner)
+		}
+	}
+
+	opts.OverLimit = func(warning string) bool {
+		spinner.Stop()
+		fmt.Println(color.New(color.FgYellow, color.Bold).Sprint("⚠ "+warning), color.New(color.FgHiBlack).Sprint("(see `ai usage`)"))
+		return confirmSend(spinner)
+	}
+
+	return ai.WithOptions(context.Background(), opts), info
+}
+
+// confirmSend asks whether to go ahead with a request, restarting the
+// spinner if so
+func confirmSend(spinner *ui.Spinner) bool {
+	fmt.Print("Send this request? [y/N] ")
+	key, err := ui.ReadKey()
+	fmt.Println()
+	if err != nil || (key != "y" && key != "Y") {
+		return false
+	}
+
+	spinner.Start(color.New(color.FgCyan).Sprint("✨ Sending..."))
+	return true
+}
+
+// printCallInfo labels answers that did not come fresh from the model
+func printCallInfo(info *ai.CallInfo) {
+	labelColor := color.New(color.FgYellow, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	switch {
+	case info.Offline && info.MatchedQuery != "":
+		fmt.Println(labelColor("📴 Offline — cached answer for a similar query:"), hintColor(info.MatchedQuery))
+	case info.Offline:
+		fmt.Println(labelColor("📴 Offline — showing cached answer"))
+	case info.Cached:
+		fmt.Println(labelColor("⚡ Cached answer"), hintColor("(use --refresh to ask again)"))
+	}
+}
+
+// takeFlag removes a command-specific switch from among the leading flags,
+// reporting whether it was present
+func takeFlag(args []string, name string) (bool, []string) {
+	found := false
+	var rest []string
+	for i, arg := range args {
+		if arg == name {
+			found = true
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		rest = append(rest, arg)
+	}
+	return found, rest
+}
+
+// parseAIFlags splits leading flags off an AI builtin's arguments
+func parseAIFlags(args []string) (aiFlags, []string, error) {
+	flags := aiFlags{
+		candidates: config.GetConfig().HpCandidates,
+	}
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch args[0] {
+		case "--":
+			return flags, args[1:], nil
+		case "--show-redacted":
+			flags.showRedacted = true
+		case "--no-cache":
+			flags.noCache = true
+		case "--refresh":
+			flags.refresh = true
+		case "-n":
+			if len(args) < 2 {
+				return flags, nil, fmt.Errorf("-n requires a count")
+			}
+			count, err := strconv.Atoi(args[1])
+			if err != nil || count < 1 || count > 9 {
+				return flags, nil, fmt.Errorf("invalid candidate count: %s", args[1])
+			}
+			flags.candidates = count
+			args = args[1:]
+		default:
+			return flags, args, nil
+		}
+		args = args[1:]
+	}
+
+	return flags, args, nil
+}
+
+// printAIError reports a failed AI call along with what the user can do about it
+func printAIError(label string, err error) {
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgYellow).SprintFunc()
+
+	if errors.Is(err, ai.ErrNoAnswer) {
+		// Not a failure as such; the model had nothing it was confident in
+		label = "Sorry, I couldn't answer that:"
+	}
+
+	fmt.Println(errorColor(label), err)
+	if hint := aiErrorHint(err); hint != "" {
+		fmt.Println(hintColor("→ " + hint))
+	}
+}
+
+// aiErrorHint suggests a fix for the typed errors returned by the ai package
+func aiErrorHint(err error) string {
+	switch {
+	case errors.Is(err, ai.ErrCancelled):
+		return ""
+	case errors.Is(err, ai.ErrNoAnswer):
+		return "Try rephrasing the request or adding more detail"
+	case errors.Is(err, auth.ErrSourceFailed):
+		return "Check the failing source with `config auth status`"
+	case errors.Is(err, auth.ErrNoCredentials):
+		return "No API key found — run `config auth login` or set $" + auth.EnvVar
+	case errors.Is(err, ai.ErrAuth):
+		return "API key invalid — run `config auth login` to enter a new one"
+	case errors.Is(err, ai.ErrQuota):
+		return "Daily quota used up — wait for it to reset or check your Gemini plan"
+	case errors.Is(err, ai.ErrRateLimited):
+		return "Too many requests — wait a moment and try again"
+	case errors.Is(err, ai.ErrBlocked):
+		return "Gemini's safety filters blocked this — try rephrasing"
+	case errors.Is(err, ai.ErrServer):
+		return "Gemini is having trouble — try again shortly"
+	case errors.Is(err, ai.ErrTimeout):
+		return "No reply in time — check your connection or raise `config set ai.timeout <seconds>`"
+	case errors.Is(err, ai.ErrBadRequest):
+		return "The request was rejected — check `config get ai.model` names a valid model"
+	}
+	return ""
+}
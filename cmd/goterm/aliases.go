@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// defaultAliasSuggestions is how many suggestions alias suggest offers
+const defaultAliasSuggestions = 8
+
+// aliasNamePattern is what an alias may be called
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// aliases holds the user's aliases, which are expanded before a command runs
+var aliases *terminal.AliasManager
+
+// handleAliasCommand lists, adds and removes aliases, and suggests new ones
+// from history. input is the line as typed, so an alias keeps its quoting.
+func handleAliasCommand(input string, args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) error {
+	if len(args) == 0 {
+		return fmt.Errorf("alias command requires a subcommand (list, add, remove, suggest)")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		listAliases()
+
+	case "add", "new":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: alias add <name> <command>, with $1 or $@ for parameters")
+		}
+		return addAlias(args[1], wordsAfter(input, 3), "")
+
+	case "remove", "rm":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: alias remove <name>")
+		}
+		if err := aliases.RemoveAlias(args[1]); err != nil {
+			return err
+		}
+		fmt.Println(color.New(color.FgGreen, color.Bold).Sprint("✓ Removed alias"), args[1])
+
+	case "suggest":
+		return suggestAliases(args[1:], line, history, spinner)
+
+	default:
+		return fmt.Errorf("unknown alias subcommand: %s", args[0])
+	}
+
+	return nil
+}
+
+// listAliases prints the aliases by name
+func listAliases() {
+	keyColor := color.New(color.FgCyan).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	list := aliases.ListAliases()
+	if len(list) == 0 {
+		fmt.Println("No aliases yet. Add one with alias add, or let alias suggest find some in your history")
+		return
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	for _, alias := range list {
+		fmt.Printf("  %s %s", keyColor(fmt.Sprintf("%-10s", alias.Name)), alias.Command)
+		if alias.Description != "" {
+			fmt.Print(hintColor("  # " + alias.Description))
+		}
+		fmt.Println()
+	}
+}
+
+// addAlias checks a name and saves the alias
+func addAlias(name, command, description string) error {
+	if err := checkAliasName(name); err != nil {
+		return err
+	}
+	if err := aliases.AddAlias(name, command, description); err != nil {
+		return err
+	}
+	fmt.Println(color.New(color.FgGreen, color.Bold).Sprint("✓ Added alias"), name, "→", command)
+	return nil
+}
+
+// checkAliasName rejects names that would hide a builtin or a program
+func checkAliasName(name string) error {
+	switch {
+	case !aliasNamePattern.MatchString(name):
+		return fmt.Errorf("alias names use letters, digits, - and _, starting with a letter")
+	case builtinCommands[name]:
+		return fmt.Errorf("%s is a built-in command", name)
+	}
+	if _, ok := config.GetConfig().Prompts.Commands[name]; ok {
+		return fmt.Errorf("%s is a custom AI command", name)
+	}
+	if _, err := exec.LookPath(name); err == nil {
+		return fmt.Errorf("%s would hide the installed %s command", name, name)
+	}
+	return nil
+}
+
+// aliasTaken reports whether a suggested name is already in use
+func aliasTaken(name string) bool {
+	if _, err := aliases.GetAlias(name); err == nil {
+		return true
+	}
+	return checkAliasName(name) != nil
+}
+
+// suggestAliases mines history for aliases, optionally has the AI name and
+// generalize them, and adds the ones the user accepts
+func suggestAliases(args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) error {
+	useAI, args := takeFlag(args, "--ai")
+	flags, args, err := parseAIFlags(args)
+	if err != nil || len(args) > 1 {
+		return fmt.Errorf("usage: alias suggest [--ai] [--show-redacted] [--no-cache|--refresh] [count]")
+	}
+	limit := defaultAliasSuggestions
+	if len(args) == 1 {
+		if limit, err = strconv.Atoi(args[0]); err != nil || limit < 1 {
+			return fmt.Errorf("count must be a positive number")
+		}
+	}
+
+	// Commands already run through an alias, or spelled as one, are covered
+	existing := aliases.ListAliases()
+	var commands []string
+	for _, command := range history.GetAll() {
+		name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+		if _, err := aliases.GetAlias(name); err != nil {
+			commands = append(commands, command)
+		}
+	}
+	var suggestions []terminal.AliasSuggestion
+	for _, s := range terminal.SuggestAliases(commands, aliasTaken, limit+len(existing)) {
+		if !aliasExists(existing, s.Command) && len(suggestions) < limit {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Printf("Nothing in the last %d commands repeats often enough to be worth an alias\n", len(commands))
+		return nil
+	}
+
+	descriptions := make([]string, len(suggestions))
+	if useAI {
+		ideas := make([]ai.AliasIdea, len(suggestions))
+		for i, s := range suggestions {
+			ideas[i] = ai.AliasIdea{Name: s.Name, Command: s.Command, Examples: s.Examples}
+		}
+
+		ctx, info := aiContext(flags, spinner)
+		spinner.Start(color.New(color.FgCyan).Sprint("✨ Naming aliases..."))
+		ideas, err = ai.ImproveAliases(ctx, ideas)
+		spinner.Stop()
+		printCallInfo(info)
+
+		if err != nil {
+			printAIError("Error naming aliases, showing the local suggestions:", err)
+		} else {
+			for i, idea := range ideas {
+				if idea.Name != suggestions[i].Name && !aliasTaken(idea.Name) {
+					suggestions[i].Rename(idea.Name)
+				}
+				// A generalized command must still produce every example,
+				// and is counted again over the history
+				if idea.Command != suggestions[i].Command && !suggestions[i].Generalize(idea.Command, commands) {
+					continue
+				}
+				descriptions[i] = idea.Description
+			}
+		}
+	}
+
+	fmt.Println(color.New(color.FgMagenta, color.Bold).Sprintf("💡 %d alias suggestions from %d commands:", len(suggestions), len(commands)))
+	added := 0
+	for i, s := range suggestions {
+		ok, quit := reviewAlias(s, descriptions[i], line)
+		if quit {
+			break
+		}
+		if ok {
+			added++
+		}
+	}
+	if added > 0 {
+		fmt.Println(color.New(color.FgHiBlack).Sprint("Aliases take effect right away; see them with alias list"))
+	}
+	return nil
+}
+
+// aliasExists reports whether an alias already stands for command
+func aliasExists(existing []terminal.Alias, command string) bool {
+	for _, alias := range existing {
+		if alias.Command == command {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewAlias shows one suggestion and adds it if the user accepts,
+// reporting whether it was added and whether the user quit
+func reviewAlias(s terminal.AliasSuggestion, description string, line *liner.State) (bool, bool) {
+	keyColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	nameColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	kind := "alias"
+	if params := s.Params(); params > 0 {
+		kind = fmt.Sprintf("function of %d parameter", params)
+		if params > 1 {
+			kind += "s"
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  %s → %s\n", nameColor(s.Name), s.Command)
+	if description != "" {
+		fmt.Println("    " + description)
+	}
+	fmt.Println(hintColor(fmt.Sprintf("    %s · used %d times · saves about %d keystrokes", kind, s.Uses, s.Saved)))
+	for _, example := range s.Examples {
+		fmt.Println(hintColor("    e.g. " + example))
+	}
+
+	for {
+		fmt.Print("  " + keyColor("[a]") + "dd  " + keyColor("[r]") + "ename  " + keyColor("[q]") + "uit  " + hintColor("any other key to skip "))
+		key, err := ui.ReadKey()
+		fmt.Println()
+		if err != nil {
+			// Not an interactive terminal: list the suggestions only
+			return false, false
+		}
+
+		switch key {
+		case "a", "A":
+			if err := addAlias(s.Name, s.Command, description); err != nil {
+				fmt.Println(errorColor("Error:"), err)
+				return false, false
+			}
+			return true, false
+
+		case "r", "R":
+			name, err := line.Prompt("  name ❯ ")
+			name = strings.TrimSpace(name)
+			if err != nil || name == "" {
+				continue
+			}
+			if _, err := aliases.GetAlias(name); err == nil {
+				fmt.Println(errorColor("Error:"), name, "is already an alias")
+				continue
+			}
+			if err := addAlias(name, s.Command, description); err != nil {
+				fmt.Println(errorColor("Error:"), err)
+				continue
+			}
+			return true, false
+
+		case "q", "Q":
+			return false, true
+
+		default:
+			return false, false
+		}
+	}
+}
+
+// wordsAfter returns input without its first n words, spacing and quotes
+// kept as typed
+func wordsAfter(input string, n int) string {
+	rest := strings.TrimSpace(input)
+	for i := 0; i < n && rest != ""; i++ {
+		if end := strings.IndexAny(rest, " \t"); end >= 0 {
+			rest = strings.TrimSpace(rest[end:])
+		} else {
+			rest = ""
+		}
+	}
+	return rest
+}
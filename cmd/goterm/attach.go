@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// maxAttachFile is the largest file read for an attachment, even when only
+// a few of its lines are sent
+const maxAttachFile = 4 << 20
+
+// binarySniff is how much of a file is checked for binary content
+const binarySniff = 8000
+
+// lineRange matches an @-reference with lines, as in @main.go:10-80,
+// @main.go:10- or @main.go:42
+var lineRange = regexp.MustCompile(`^(.+):(\d+)(-(\d*))?$`)
+
+// parseAttachments reads the @file, @file:10-80 and @last references in a
+// chat message, within the attach budget. The message is kept as typed;
+// @@ stands for a literal @ and is returned unescaped. A plain @word that
+// can't be attached, such as @types/node or @john, stays in the message as
+// text, and why is returned in skipped. Only @last and a reference with a
+// line range fail the message.
+func parseAttachments(text string) (string, []ai.Attachment, []string, error) {
+	budget := config.GetConfig().Chat.AttachBudget
+	var attachments []ai.Attachment
+	var skipped []string
+
+	words := strings.Fields(text)
+	for i, word := range words {
+		if strings.HasPrefix(word, "@@") {
+			words[i] = word[1:]
+			continue
+		}
+		if len(word) < 2 || word[0] != '@' {
+			continue
+		}
+
+		var attachment ai.Attachment
+		var err error
+		if budget <= 0 {
+			err = fmt.Errorf("the attach budget (chat.attach_budget) is used up before %s", word)
+		} else {
+			attachment, err = readAttachment(word[1:], budget)
+		}
+		if err != nil {
+			if !explicitReference(word[1:]) {
+				skipped = append(skipped, err.Error())
+				continue
+			}
+			return "", nil, nil, err
+		}
+		budget -= attachment.Size
+		attachments = append(attachments, attachment)
+	}
+
+	return strings.Join(words, " "), attachments, skipped, nil
+}
+
+// explicitReference reports whether a reference without its @ can only
+// mean an attachment: @last or a file with a line range
+func explicitReference(ref string) bool {
+	ref = strings.TrimRight(ref, ",.?!;:)")
+	return ref == "last" || lineRange.MatchString(ref)
+}
+
+// readAttachment resolves one reference without its @
+func readAttachment(ref string, budget int) (ai.Attachment, error) {
+	if strings.TrimRight(ref, ",.?!;:)") == "last" {
+		return lastRunAttachment(budget)
+	}
+
+	// Punctuation after a name, as in "@go.mod?", isn't part of it
+	path, first, last := ref, 0, 0
+	if _, err := os.Stat(expandHome(path)); err != nil {
+		if trimmed := strings.TrimRight(ref, ",.?!;:)"); trimmed != "" {
+			path = trimmed
+		}
+		if match := lineRange.FindStringSubmatch(path); match != nil {
+			path = match[1]
+			first, _ = strconv.Atoi(match[2])
+			last = first
+			if match[3] != "" {
+				last, _ = strconv.Atoi(match[4]) // 0 means to the end
+			}
+		}
+	}
+
+	data, err := readTextFile(expandHome(path))
+	if err != nil {
+		return ai.Attachment{}, err
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	total := len(lines)
+
+	attachment := ai.Attachment{Name: path}
+	if first > 0 {
+		if last == 0 || last > total {
+			last = total
+		}
+		if first > last {
+			return ai.Attachment{}, fmt.Errorf("@%s has %d lines, so %d-%d selects nothing", path, total, first, last)
+		}
+		lines = lines[first-1 : last]
+		attachment.Note = fmt.Sprintf("lines %d-%d of %d", first, last, total)
+	} else {
+		first = 1
+	}
+
+	content, kept := fitLines(lines, budget)
+	if kept == 0 {
+		return ai.Attachment{}, fmt.Errorf("@%s: line %d alone is over the attach budget", path, first)
+	}
+	if kept < len(lines) {
+		attachment.Note = fmt.Sprintf("lines %d-%d of %d, cut to fit; pick others with @%s:A-B", first, first+kept-1, total, path)
+	}
+	attachment.Content = content
+	attachment.Size = len(content)
+	return attachment, nil
+}
+
+// lastRunAttachment attaches the command and output of the last run
+func lastRunAttachment(budget int) (ai.Attachment, error) {
+	if len(recentRuns) == 0 {
+		return ai.Attachment{}, errors.New("@last: no command output captured yet in this session")
+	}
+	run := recentRuns[len(recentRuns)-1]
+
+	var b strings.Builder
+	b.WriteString("$ " + run.Command.Raw + "\n")
+	if run.Output.Stdout != "" {
+		b.WriteString(strings.TrimRight(run.Output.Stdout, "\n") + "\n")
+	}
+	if run.Output.Stderr != "" {
+		b.WriteString("[stderr]\n" + strings.TrimRight(run.Output.Stderr, "\n") + "\n")
+	}
+	fmt.Fprintf(&b, "[exit status %d]\n", run.Output.ExitCode)
+
+	lines := strings.SplitAfter(b.String(), "\n")
+	content, kept := fitLines(lines, budget)
+	attachment := ai.Attachment{Name: "output of " + run.Command.Raw, Content: content, Size: len(content)}
+	if kept < len(lines) {
+		attachment.Note = "cut to fit"
+	}
+	return attachment, nil
+}
+
+// readTextFile reads a file for attaching, refusing directories, very
+// large files and binary content
+func readTextFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("@%s: %w", path, errors.Unwrap(err))
+	}
+	switch {
+	case info.IsDir():
+		return nil, fmt.Errorf("@%s is a directory", path)
+	case info.Size() > maxAttachFile:
+		return nil, fmt.Errorf("@%s is too large to attach (%d MB)", path, info.Size()>>20)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := data[:min(len(data), binarySniff)]
+	if bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(trimPartialRune(sample)) {
+		return nil, fmt.Errorf("@%s looks like a binary file, not attached", path)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("@%s is empty", path)
+	}
+	return data, nil
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off at the end of a sample
+func trimPartialRune(sample []byte) []byte {
+	for i := 0; i < utf8.UTFMax && i < len(sample); i++ {
+		if utf8.Valid(sample[:len(sample)-i]) {
+			return sample[:len(sample)-i]
+		}
+	}
+	return sample
+}
+
+// fitLines joins as many whole lines as fit in budget bytes and returns
+// them with the number kept
+func fitLines(lines []string, budget int) (string, int) {
+	var b strings.Builder
+	for i, line := range lines {
+		if b.Len()+len(line) > budget {
+			return b.String(), i
+		}
+		b.WriteString(line)
+	}
+	return b.String(), len(lines)
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// completeAttachment completes the @-reference at the end of a chat line
+func completeAttachment(input, word string) []string {
+	base := strings.TrimSuffix(input, word)
+	candidates := []string{}
+	if strings.HasPrefix("@last", word) {
+		candidates = append(candidates, base+"@last")
+	}
+
+	matches, _ := filepath.Glob(expandHome(word[1:]) + "*")
+	for _, match := range matches {
+		if strings.HasPrefix(word, "@~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				match = "~" + strings.TrimPrefix(match, home)
+			}
+		}
+		if info, err := os.Stat(expandHome(match)); err == nil && info.IsDir() {
+			match += "/"
+		}
+		candidates = append(candidates, base+"@"+match)
+	}
+	return candidates
+}
+
+// printAttachments lists what is being sent with a message, and the
+// @-words left in it as text
+func printAttachments(attachments []ai.Attachment, skipped []string) {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	for _, reason := range skipped {
+		fmt.Println(hintColor("📎 kept as text, " + reason + " (write @@ to mean a literal @)"))
+	}
+	if len(attachments) == 0 {
+		return
+	}
+
+	labels := make([]string, len(attachments))
+	for i, a := range attachments {
+		labels[i] = a.Label()
+	}
+	fmt.Println(hintColor("📎 " + strings.Join(labels, "  ·  ")))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAttachments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	notes := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(notes, []byte("one\ntwo\nthree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message     string
+		text        string
+		attached    int
+		skipped     int
+		wantFailure bool
+	}{
+		{"what is in @" + notes + "?", "what is in @" + notes + "?", 1, 0, false},
+		{"explain @" + notes + ":2-3", "explain @" + notes + ":2-3", 1, 0, false},
+
+		// Words that only look like references stay in the message
+		{"what does @types/node provide", "what does @types/node provide", 0, 1, false},
+		{"ask @john about it", "ask @john about it", 0, 1, false},
+		{"what does @@Override do", "what does @Override do", 0, 0, false},
+
+		// These can only mean an attachment
+		{"explain @missing.go:10-20", "", 0, 0, true},
+		{"explain @" + notes + ":9", "", 0, 0, true},
+		{"why did @last fail", "", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		text, attachments, skipped, err := parseAttachments(tt.message)
+		if tt.wantFailure {
+			if err == nil {
+				t.Errorf("parseAttachments(%q) succeeded, want an error", tt.message)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseAttachments(%q): %v", tt.message, err)
+			continue
+		}
+		if text != tt.text || len(attachments) != tt.attached || len(skipped) != tt.skipped {
+			t.Errorf("parseAttachments(%q) = %q, %d attached, %d skipped; want %q, %d, %d",
+				tt.message, text, len(attachments), len(skipped), tt.text, tt.attached, tt.skipped)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// fixOffer is an AI fix being fetched in the background for a failed command
+type fixOffer struct {
+	failed     *terminal.CommandLog
+	cancel     context.CancelFunc
+	done       chan struct{}
+	suggestion *ai.Suggestion
+	err        error
+}
+
+var (
+	// lastFailure is the most recent failed command of this session
+	lastFailure *terminal.CommandLog
+
+	// pendingFix is offered before the next prompt
+	pendingFix *fixOffer
+
+	// fixAccepted is set when Alt+F is pressed at the prompt
+	fixAccepted bool
+)
+
+// noteResult remembers a failed command and, when auto-fix is enabled,
+// starts fetching a fix so accepting it is instant
+func noteResult(entry *terminal.CommandLog) {
+	if entry == nil || entry.Output.ExitCode == 0 {
+		return
+	}
+	lastFailure = entry
+
+	if !config.GetConfig().AutoFix.Enabled {
+		return
+	}
+
+	if pendingFix != nil {
+		pendingFix.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	offer := &fixOffer{failed: entry, cancel: cancel, done: make(chan struct{})}
+	pendingFix = offer
+
+	// Copy the entry now, as the main loop keeps using it
+	failed := toAILog(entry)
+	go func() {
+		defer close(offer.done)
+		offer.suggestion, offer.err = ai.GenerateFix(ctx, failed)
+	}()
+}
+
+// toAILog converts a terminal log entry for the ai package
+func toAILog(entry *terminal.CommandLog) *ai.CommandLog {
+	converted := ai.CommandLog(*entry)
+	return &converted
+}
+
+// fixKeys accept a pending fix from the prompt; Option+F on macOS
+// keyboards types ƒ
+var fixKeys = []string{"alt+f", "ƒ"}
+
+// armFix shows the hint for a pending fix and binds Alt+F at the next
+// prompt. It returns the offer, or nil when there is none.
+func armFix() *fixOffer {
+	offer := pendingFix
+	pendingFix = nil
+	fixAccepted = false
+	if offer == nil {
+		return nil
+	}
+
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	fmt.Println(hintColor(fmt.Sprintf("exit %d — press Alt+F for an AI fix", offer.failed.Output.ExitCode)))
+
+	for _, key := range fixKeys {
+		lineEditor.Bind(key, "", func(context.Context, string, int) ui.EditResult {
+			fixAccepted = true
+			return ui.EditResult{Accept: true}
+		})
+	}
+	return offer
+}
+
+// settleFix removes the Alt+F binding after the prompt and, if the fix was
+// accepted, opens it in the review flow. Otherwise the fetch is abandoned.
+func settleFix(offer *fixOffer, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	if offer == nil {
+		return
+	}
+	for _, key := range fixKeys {
+		lineEditor.Unbind(key)
+	}
+
+	if !fixAccepted {
+		offer.cancel()
+		return
+	}
+
+	select {
+	case <-offer.done:
+	default:
+		spinner.Start(color.New(color.FgCyan).Sprint("✨ Finishing the fix..."))
+		<-offer.done
+		spinner.Stop()
+	}
+
+	if offer.err != nil {
+		printAIError("Error getting AI fix:", offer.err)
+		return
+	}
+
+	reviewSuggestions([]*ai.Suggestion{offer.suggestion}, line, history, spinner)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/manpage"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/pkg/utils"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// maxAnnotationLines caps how far one explanation wraps
+const maxAnnotationLines = 3
+
+// printBreakdown shows a command with each token's meaning hanging off it
+// on connector lines, explainshell style:
+//
+//	tar -xzf a.tgz
+//	│   │    └─ value for -f (ARCHIVE)
+//	│   └─ -x, --extract ...
+//	└─ tar: an archiving utility
+func printBreakdown(command string, annotations []manpage.Annotation) {
+	lineColor := color.New(color.FgHiBlack).SprintFunc()
+	sourceColor := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Println("  " + terminal.NewHighlighter().Highlight(command))
+
+	// Columns of each token, counted in runes so wide input still lines up
+	columns := make([]int, len(annotations))
+	for i, a := range annotations {
+		columns[i] = utf8.RuneCountInString(command[:a.Token.Pos])
+	}
+
+	width := utils.GetTerminalWidth()
+	for i := len(annotations) - 1; i >= 0; i-- {
+		a := annotations[i]
+
+		// Connectors for the tokens to the left stay open
+		prefix := make([]rune, columns[i])
+		for j := range prefix {
+			prefix[j] = ' '
+		}
+		for _, column := range columns[:i] {
+			prefix[column] = '│'
+		}
+
+		text := a.Text
+		if a.Source != "" {
+			text += " " + sourceColor("("+a.Source+")")
+		}
+
+		room := width - columns[i] - 8
+		if room < 20 {
+			room = 20
+		}
+		lines := wrapText(text, room)
+		if len(lines) > maxAnnotationLines {
+			lines = append(lines[:maxAnnotationLines-1], lines[maxAnnotationLines-1]+" …")
+		}
+
+		fmt.Println("  " + lineColor(string(prefix)+"└─ ") + lines[0])
+		for _, line := range lines[1:] {
+			fmt.Println("  " + lineColor(string(prefix)+"   ") + line)
+		}
+	}
+}
+
+// wrapText breaks text into lines of at most width visible characters
+func wrapText(text string, width int) []string {
+	var lines []string
+	current := ""
+
+	for _, word := range strings.Fields(text) {
+		if current != "" && utf8.RuneCountInString(utils.StripAnsi(current+" "+word)) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		if current == "" {
+			current = word
+		} else {
+			current += " " + word
+		}
+	}
+
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
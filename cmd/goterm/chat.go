@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+var chatSlashCommands = []string{"/save", "/load", "/list", "/clear", "/system", "/help", "/exit"}
+
+// lineCompleter is the completer currently installed on the shared liner
+// state; liner has no getter, so it is tracked here for restoring.
+var lineCompleter liner.Completer
+
+// setLineCompleter installs f on line and returns the completer it replaced
+func setLineCompleter(line *liner.State, f liner.Completer) liner.Completer {
+	previous := lineCompleter
+	lineCompleter = f
+	line.SetCompleter(f)
+	return previous
+}
+
+// runChatSession runs the interactive multi-turn chat sub-mode
+func runChatSession(ctx context.Context, line *liner.State, spinner *ui.Spinner) {
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	conversation := ai.NewConversation()
+
+	fmt.Println(headerColor("💬 Chat mode"), hintColor("— type /help for commands, /exit to leave"))
+
+	previous := setLineCompleter(line, func(input string) (candidates []string) {
+		if words := strings.Fields(input); len(words) > 0 && strings.HasPrefix(words[len(words)-1], "@") && !strings.HasSuffix(input, " ") {
+			return completeAttachment(input, words[len(words)-1])
+		}
+		if !strings.HasPrefix(input, "/") {
+			return nil
+		}
+		for _, cmd := range chatSlashCommands {
+			if strings.HasPrefix(cmd, input) {
+				candidates = append(candidates, cmd)
+			}
+		}
+		return candidates
+	})
+	defer setLineCompleter(line, previous)
+
+	for {
+		prompt := "chat ❯ "
+		if conversation.Name != "" {
+			prompt = fmt.Sprintf("chat:%s ❯ ", conversation.Name)
+		}
+
+		input, err := line.Prompt(prompt)
+		if err == io.EOF {
+			fmt.Println()
+			return
+		} else if err != nil {
+			if err == liner.ErrPromptAborted || err.Error() == "Interrupted" {
+				continue
+			}
+			fmt.Println(errorColor("Error reading input:"), err)
+			return
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		if strings.HasPrefix(input, "/") {
+			if done := handleChatCommand(input, &conversation); done {
+				return
+			}
+			continue
+		}
+
+		text, attachments, skipped, err := parseAttachments(input)
+		if err != nil {
+			fmt.Println(errorColor("Error:"), err)
+			continue
+		}
+		printAttachments(attachments, skipped)
+
+		spinner.Start(color.New(color.FgCyan).Sprint("✨ Thinking..."))
+		reply, err := conversation.Send(ctx, text, attachments)
+		spinner.Stop()
+
+		if err != nil {
+			printAIError("Error getting answer:", err)
+		} else {
+			printBox(reply)
+		}
+	}
+}
+
+// handleChatCommand processes a slash command inside the chat session.
+// It returns true when the session should end.
+func handleChatCommand(input string, conversation **ai.Conversation) bool {
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	parts := strings.Fields(input)
+	arg := strings.TrimSpace(strings.TrimPrefix(input, parts[0]))
+	current := *conversation
+
+	switch parts[0] {
+	case "/exit", "/quit":
+		return true
+
+	case "/help":
+		fmt.Println("  /save [name]    " + hintColor("save this conversation"))
+		fmt.Println("  /load <name>    " + hintColor("resume a saved conversation"))
+		fmt.Println("  /list           " + hintColor("list saved conversations"))
+		fmt.Println("  /clear          " + hintColor("forget all turns, keeping the system prompt"))
+		fmt.Println("  /system [text]  " + hintColor("show or replace the system prompt"))
+		fmt.Println("  /exit           " + hintColor("leave chat mode"))
+		fmt.Println("  @file @file:10-80 @last  " + hintColor("attach a file, some of its lines, or the last command's output"))
+
+	case "/save":
+		name := arg
+		if name == "" {
+			name = current.Name
+		}
+		if name == "" {
+			name = current.Created.Format("20060102-150405")
+		}
+		if err := ai.SaveConversation(current, name); err != nil {
+			fmt.Println(errorColor("Error saving conversation:"), err)
+			return false
+		}
+		fmt.Println(successColor("✓ Saved conversation"), current.Name)
+
+	case "/load":
+		if arg == "" {
+			fmt.Println(errorColor("Usage:"), "/load <name>")
+			return false
+		}
+		loaded, err := ai.LoadConversation(arg)
+		if err != nil {
+			fmt.Println(errorColor("Error loading conversation:"), err)
+			return false
+		}
+		*conversation = loaded
+		fmt.Printf("%s %s (%d messages)\n", successColor("✓ Loaded conversation"), loaded.Name, len(loaded.Messages))
+
+	case "/list":
+		infos, err := ai.ListConversations()
+		if err != nil {
+			fmt.Println(errorColor("Error listing conversations:"), err)
+			return false
+		}
+		if len(infos) == 0 {
+			fmt.Println(hintColor("No saved conversations"))
+			return false
+		}
+		for _, info := range infos {
+			fmt.Printf("  %s %s\n", info.Name,
+				hintColor(fmt.Sprintf("(%d messages, %s)", info.Messages, info.Updated.Format("2006-01-02 15:04"))))
+		}
+
+	case "/clear":
+		current.Clear()
+		fmt.Println(successColor("✓ Conversation cleared"))
+
+	case "/system":
+		if arg == "" {
+			fmt.Println(hintColor("System prompt:"))
+			fmt.Println(current.System)
+			return false
+		}
+		current.System = arg
+		fmt.Println(successColor("✓ System prompt updated"))
+
+	default:
+		fmt.Println(errorColor("Unknown chat command:"), parts[0], hintColor("(try /help)"))
+	}
+
+	return false
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"os/exec"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// commitTemplateHelp is appended below the message when it is opened in
+// $EDITOR; like git, lines starting with # are dropped
+const commitTemplateHelp = `
+# Edit the commit message. Lines starting with '#' are ignored,
+# and an empty message cancels the commit.
+`
+
+// handleCommitCommand writes a commit message for the staged changes and
+// offers to commit with it
+func handleCommitCommand(args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) error {
+	style := config.GetConfig().Commit.Style
+	if len(args) > 0 && args[0] == "--style" {
+		if len(args) < 2 {
+			return fmt.Errorf("--style requires one of: %s", strings.Join(ai.CommitStyles(), ", "))
+		}
+		style = args[1]
+		args = args[2:]
+	}
+
+	flags, rest, err := parseAIFlags(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("usage: gcm [--style %s] [--show-redacted] [--no-cache|--refresh]", strings.Join(ai.CommitStyles(), "|"))
+	}
+
+	diff, err := git("diff", "--cached", "--no-color")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return errors.New("nothing staged to commit (use git add first)")
+	}
+	stat, err := git("diff", "--cached", "--stat", "--no-color")
+	if err != nil {
+		return err
+	}
+
+	for {
+		ctx, info := aiContext(flags, spinner)
+		spinner.Start(color.New(color.FgCyan).Sprint("✨ Writing commit message..."))
+		message, err := ai.GenerateCommitMessage(ctx, diff, stat, style, config.GetConfig().Commit.DiffBudget)
+		spinner.Stop()
+		printCallInfo(info)
+
+		if err != nil {
+			printAIError("Error writing commit message:", err)
+			return nil
+		}
+
+		if !reviewCommitMessage(message, stat, line, history, spinner) {
+			return nil
+		}
+
+		// Regenerating should not return the cached answer
+		flags.refresh = true
+	}
+}
+
+// reviewCommitMessage shows the message and the git command that uses it,
+// then runs, edits or copies it. It returns true to ask for a new message.
+func reviewCommitMessage(message string, stat string, line *liner.State, history *terminal.History, spinner *ui.Spinner) bool {
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	keyColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	for {
+		fmt.Println(headerColor("📝 Commit message:"))
+		printBox(message)
+		command := commitCommand(message)
+
+		fmt.Print(keyColor("[r]") + "un  " + keyColor("[e]") + "dit in $EDITOR  " + keyColor("[g]") + "enerate again  " +
+			keyColor("[c]") + "opy  " + hintColor("any other key to skip "))
+		key, err := ui.ReadKey()
+		fmt.Println()
+
+		if err != nil {
+			// Not an interactive terminal: show the command to run
+			fmt.Println(color.New(color.FgHiCyan, color.Bold).Sprint(command))
+			return false
+		}
+
+		switch key {
+		case "r", "R":
+			fmt.Println(hintColor(command))
+			runReviewedCommand(command, "low", line, history, spinner)
+			return false
+
+		case "e", "E":
+			template := message + "\n" + commitTemplateHelp + "#\n# Staged changes:\n" + commentLines(stat)
+			edited, err := ui.EditText(template, "COMMIT_EDITMSG-*.txt")
+			if err != nil {
+				fmt.Println(errorColor("Error running editor:"), err)
+				continue
+			}
+			edited = stripComments(edited)
+			if edited == "" {
+				fmt.Println(color.New(color.FgYellow).Sprint("Empty message, commit cancelled."))
+				return false
+			}
+			message = edited
+
+		case "g", "G":
+			return true
+
+		case "c", "C":
+			if err := clipboard.Write(command); err == nil {
+				fmt.Println(successColor("✓ Command copied to clipboard"))
+			} else {
+				fmt.Println(errorColor("Could not copy to clipboard:"), err)
+			}
+			return false
+
+		default:
+			return false
+		}
+	}
+}
+
+// commitCommand builds the git command for a message: the subject and each
+// following paragraph become separate -m arguments, as git joins them
+func commitCommand(message string) string {
+	args := []string{"git", "commit"}
+	for _, paragraph := range strings.Split(message, "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			args = append(args, "-m", terminal.Quote(paragraph))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// git runs a git subcommand and returns its output
+func git(args ...string) (string, error) {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
+
+// commentLines prefixes every line with "# "
+func commentLines(text string) string {
+	var b strings.Builder
+	for _, l := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString("#" + strings.TrimRight(" "+l, " ") + "\n")
+	}
+	return b.String()
+}
+
+// stripComments drops lines starting with # and surrounding blank lines
+func stripComments(text string) string {
+	var kept []string
+	for _, l := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(l, "#") {
+			kept = append(kept, strings.TrimRight(l, " \t\r"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
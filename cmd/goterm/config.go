@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/auth"
+	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/environment"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// handleConfigCommand manages GO-TERM settings
+func handleConfigCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("config command requires a subcommand (list, get, set, context, auth, clipboard)")
+	}
+
+	keyColor := color.New(color.FgCyan).SprintFunc()
+
+	switch args[0] {
+	case "list", "ls":
+		keys := config.Keys()
+		for _, name := range config.SortedKeys(keys) {
+			fmt.Printf("  %s = %s\n", keyColor(name), keys[name])
+		}
+
+	case "get":
+		if len(args) < 2 {
+			return fmt.Errorf("config get requires a key")
+		}
+		value, err := config.Get(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+
+	case "set":
+		if len(args) < 3 {
+			return fmt.Errorf("config set requires a key and value")
+		}
+		// The value is the rest of the line, so unquoted words are kept too
+		if err := config.Set(args[1], strings.Join(args[2:], " ")); err != nil {
+			return err
+		}
+		// The setting may be the key or the command that supplies it
+		auth.Forget()
+		value, _ := config.Get(args[1])
+		fmt.Printf("Set %s = %s\n", keyColor(args[1]), value)
+
+	case "context":
+		// Preview the environment block sent with AI prompts
+		block := environment.Default.Collect("")
+		if block == "" {
+			fmt.Println("Environment context is disabled (config set context.enabled true)")
+			return nil
+		}
+		fmt.Println(block)
+
+	case "auth":
+		return handleAuthCommand(args[1:])
+
+	case "clipboard":
+		showClipboardBackends()
+
+	default:
+		return fmt.Errorf("unknown config subcommand: %s", args[0])
+	}
+
+	return nil
+}
+
+// showClipboardBackends lists the clipboard backends, marking which ones
+// copy and paste in this session
+func showClipboardBackends() {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	activeColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+
+	setting, _ := config.Get("clipboard.backend")
+	fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint("📋 Clipboard backends") + hintColor(" (clipboard.backend = "+setting+")"))
+
+	writer, writeErr := clipboard.Writer()
+	reader, readErr := clipboard.Reader()
+	for _, b := range clipboard.Backends() {
+		var roles []string
+		if writeErr == nil && writer.Name() == b.Name() {
+			roles = append(roles, "copies")
+		}
+		if readErr == nil && reader.Name() == b.Name() {
+			roles = append(roles, "pastes")
+		}
+
+		status := hintColor("not detected")
+		if b.Available() {
+			status = "detected"
+		}
+		line := fmt.Sprintf("  %-8s %s", b.Name(), status)
+		if len(roles) > 0 {
+			line += "  " + activeColor("← "+strings.Join(roles, " and "))
+		}
+		fmt.Println(line)
+	}
+
+	if writeErr != nil {
+		fmt.Println(color.New(color.FgYellow).Sprint("⚠ ") + writeErr.Error())
+	}
+}
+
+// handleAuthCommand stores, inspects or removes the API key
+func handleAuthCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("config auth requires a subcommand (login, status, logout)")
+	}
+
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	switch args[0] {
+	case "login":
+		preferFile := len(args) > 1 && args[1] == "--file"
+		key, err := ui.ReadSecret("Gemini API key: ")
+		if err != nil {
+			return err
+		}
+		source, err := auth.Login(key, preferFile)
+		if err != nil {
+			return err
+		}
+		fmt.Println(successColor("✓ API key saved to the "+source), hintColor("("+auth.Mask(key)+")"))
+
+	case "status":
+		_, active, _ := auth.Resolve()
+		for _, status := range auth.Status() {
+			marker := "  "
+			if status.Source == active {
+				marker = successColor("➜ ")
+			}
+
+			state := hintColor("not set")
+			switch {
+			case !status.Available:
+				state = hintColor("unavailable")
+			case status.Err != nil:
+				state = color.RedString("error: %v", status.Err)
+			case status.Key != "":
+				state = status.Key
+			}
+			fmt.Printf("%s%-8s %s %s\n", marker, status.Source, state, hintColor(status.Detail))
+		}
+		if active == "" {
+			fmt.Println(hintColor("No API key found — run `config auth login`"))
+		}
+
+	case "logout":
+		removed, err := auth.Logout()
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			fmt.Println("No stored API key to remove")
+		} else {
+			fmt.Println(successColor("✓ Removed API key from the " + strings.Join(removed, " and ")))
+		}
+		if _, source, err := auth.Resolve(); err == nil {
+			fmt.Println(hintColor("A key is still supplied by the " + source + " source"))
+		}
+
+	default:
+		return fmt.Errorf("unknown config auth subcommand: %s", args[0])
+	}
+
+	return nil
+}
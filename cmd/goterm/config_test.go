@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"testing"
+)
+
+func TestConfigSetMultiWordValue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`config set auth.command "pass show gemini"`, "pass show gemini"},
+		{`config set auth.command 'op read op://vault/gemini'`, "op read op://vault/gemini"},
+		{`config set auth.command pass show gemini`, "pass show gemini"},
+	}
+
+	for _, tt := range tests {
+		args, err := terminal.SplitArgs(tt.line)
+		if err != nil {
+			t.Fatalf("SplitArgs(%q): %v", tt.line, err)
+		}
+		if err := handleConfigCommand(args[1:]); err != nil {
+			t.Fatalf("%s: %v", tt.line, err)
+		}
+		if got, _ := config.Get("auth.command"); got != tt.want {
+			t.Errorf("%s: auth.command = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/policy"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+var (
+	// lineEditor reads the main prompt
+	lineEditor = ui.NewLineEditor()
+
+	// clipboardSuggestion is offered by Tab on an empty line
+	clipboardSuggestion string
+)
+
+// setupLineEditor loads history and installs the completer and the Ctrl+G
+// natural-language binding
+func setupLineEditor(history *terminal.History) {
+	if f, err := os.Open(terminal.GetHistoryFilePath()); err == nil {
+		lineEditor.ReadHistory(f)
+		f.Close()
+	}
+
+	lineEditor.Complete = func(input string) []string {
+		if input == "" {
+			if clipboardSuggestion != "" {
+				return []string{clipboardSuggestion}
+			}
+			return nil
+		}
+
+		// Find matching history command
+		suggestion := history.GetRecent(input)
+		if suggestion != "" && suggestion != input && strings.HasPrefix(suggestion, input) {
+			return []string{suggestion}
+		}
+		return nil
+	}
+
+	lineEditor.Bind("ctrl+g", "✨ generating...", generateInline)
+}
+
+// generateInline replaces a description in the line buffer, optionally
+// written as a "# comment", with a generated command. Undo brings the
+// description back. The description may start with the AI flags, such as
+// --show-redacted, and Ctrl+C or Esc stops the request.
+func generateInline(ctx context.Context, text string, cursor int) ui.EditResult {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	unchanged := ui.EditResult{Line: text, Cursor: cursor}
+
+	flags, words, err := parseAIFlags(strings.Fields(strings.TrimPrefix(strings.TrimSpace(text), "#")))
+	if err != nil {
+		unchanged.Message = errorColor("Error: ") + err.Error()
+		return unchanged
+	}
+	query := strings.Join(words, " ")
+	if query == "" {
+		unchanged.Message = hintColor("Describe what you want to do, then press Ctrl+G")
+		return unchanged
+	}
+
+	spinner := ui.NewSpinner()
+	defer spinner.Stop()
+	aiCtx, info := aiContext(flags, spinner)
+	aiCtx, cancel := context.WithCancel(aiCtx)
+	defer cancel()
+	defer context.AfterFunc(ctx, cancel)()
+
+	suggestion, err := ai.GenerateCommandForHp(aiCtx, query)
+	if errors.Is(err, context.Canceled) || errors.Is(err, ai.ErrCancelled) {
+		unchanged.Message = hintColor("Cancelled")
+		return unchanged
+	} else if err != nil {
+		unchanged.Message = errorColor("Could not generate a command: ") + err.Error()
+		return unchanged
+	}
+
+	notes := []string{"Ctrl+Z restores your text"}
+	if assessment := policy.Check(suggestion.Command, suggestion.RiskLevel); assessment.Risk != policy.RiskLow {
+		notes = append([]string{"● " + assessment.Risk.String() + " risk"}, notes...)
+	}
+	if info.Offline {
+		notes = append([]string{"📴 offline, cached answer"}, notes...)
+	} else if info.Cached {
+		notes = append([]string{"⚡ cached"}, notes...)
+	}
+
+	message := hintColor(strings.Join(notes, " · "))
+	if suggestion.Explanation != "" {
+		message = color.New(color.FgHiWhite).Sprint(suggestion.Explanation) + "\n" + message
+	}
+
+	return ui.EditResult{Line: suggestion.Command, Cursor: -1, Message: message}
+}
+
+// appendHistory records a command in the prompt history of both editors and
+// saves it
+func appendHistory(line *liner.State, command string) {
+	line.AppendHistory(command)
+	lineEditor.AppendHistory(command)
+
+	if f, err := os.Create(terminal.GetHistoryFilePath()); err == nil {
+		lineEditor.WriteHistory(f)
+		f.Close()
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/eval"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxAnswerWidth is how much of an answer the eval report shows
+const maxAnswerWidth = 100
+
+// runEval runs YAML suites of cases against the AI commands and prints a
+// pass-rate report. It is reached as "goterm eval" and returns the exit
+// status.
+func runEval(args []string) int {
+	flags := flag.NewFlagSet("goterm eval", flag.ContinueOnError)
+	record := flags.Bool("record", false, "call the provider and save its answers as fixtures")
+	replay := flags.Bool("replay", false, "answer from saved fixtures, offline")
+	fixtures := flags.String("fixtures", "", "fixture directory (default: fixtures/ next to the first suite)")
+	run := flags.String("run", "", "only run cases whose name matches this regex")
+	minPass := flags.Float64("min-pass", 100, "lowest pass rate, in percent, that still exits 0")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: goterm eval [--record|--replay] [--fixtures dir] [--run regex] [--min-pass percent] suite.yaml...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 || (*record && *replay) {
+		flags.Usage()
+		return 2
+	}
+
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	var filter *regexp.Regexp
+	if *run != "" {
+		var err error
+		if filter, err = regexp.Compile(*run); err != nil {
+			fmt.Fprintln(os.Stderr, errorColor("Error:"), "--run:", err)
+			return 2
+		}
+	}
+
+	var suites []*eval.Suite
+	for _, path := range flags.Args() {
+		suite, err := eval.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, errorColor("Error:"), err)
+			return 2
+		}
+		suites = append(suites, suite)
+	}
+
+	eval.Reproducible()
+
+	if *record || *replay {
+		dir := *fixtures
+		if dir == "" {
+			dir = filepath.Join(filepath.Dir(suites[0].Path), "fixtures")
+		}
+		mode := ai.FixturesRecord
+		if *replay {
+			mode = ai.FixturesReplay
+			// Replayed answers cost nothing
+			config.GetConfig().Usage.Enabled = false
+		}
+		ai.UseFixtures(dir, mode)
+	}
+
+	ctx := ai.WithOptions(context.Background(), ai.RequestOptions{NoCache: true})
+
+	passed, total := 0, 0
+	for _, suite := range suites {
+		fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint("📋 " + suite.Path))
+		results := eval.Run(ctx, suite, filter, printEvalResult)
+
+		suitePassed := 0
+		for _, result := range results {
+			if result.Passed() {
+				suitePassed++
+			}
+		}
+		if len(results) > 0 {
+			fmt.Printf("   %d/%d passed\n\n", suitePassed, len(results))
+		}
+		passed += suitePassed
+		total += len(results)
+	}
+
+	if total == 0 {
+		fmt.Println("No cases matched")
+		return 1
+	}
+
+	rate := float64(passed) / float64(total) * 100
+	summary := fmt.Sprintf("Pass rate: %.1f%% (%d/%d)", rate, passed, total)
+	if rate < *minPass {
+		fmt.Println(errorColor(summary))
+		return 1
+	}
+	fmt.Println(color.New(color.FgGreen, color.Bold).Sprint(summary))
+	return 0
+}
+
+// printEvalResult prints one line per case, with the reasons it failed
+func printEvalResult(result eval.Result) {
+	passColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	failColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	answer := strings.Join(strings.Fields(result.Answer), " ")
+	if runes := []rune(answer); len(runes) > maxAnswerWidth {
+		answer = string(runes[:maxAnswerWidth]) + "…"
+	}
+	timing := hintColor(fmt.Sprintf("(%.1fs)", result.Duration.Seconds()))
+
+	switch {
+	case result.Err != nil:
+		fmt.Printf("  %s %s %s\n", failColor("✗"), result.Case.Name, timing)
+		fmt.Printf("      %s\n", failColor("error: ")+result.Err.Error())
+	case result.Passed():
+		fmt.Printf("  %s %s %s\n", passColor("✓"), result.Case.Name, timing)
+		fmt.Printf("      %s\n", hintColor(answer))
+	default:
+		fmt.Printf("  %s %s %s\n", failColor("✗"), result.Case.Name, timing)
+		fmt.Printf("      %s\n", answer)
+		for _, problem := range result.Problems {
+			fmt.Printf("      %s\n", failColor("→ ")+problem)
+		}
+	}
+}
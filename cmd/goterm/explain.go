@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/manpage"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxRecentRuns bounds the commands kept for hx in this session
+const maxRecentRuns = 50
+
+// recentRuns holds the latest commands of this session with their output
+var recentRuns []*terminal.CommandLog
+
+// recordRun keeps a finished command for hx
+func recordRun(entry *terminal.CommandLog) {
+	if entry == nil {
+		return
+	}
+	recentRuns = append(recentRuns, entry)
+	if len(recentRuns) > maxRecentRuns {
+		recentRuns = recentRuns[len(recentRuns)-maxRecentRuns:]
+	}
+}
+
+// previousRun finds the run of the same command before the latest one
+func previousRun() *terminal.CommandLog {
+	latest := recentRuns[len(recentRuns)-1]
+	for i := len(recentRuns) - 2; i >= 0; i-- {
+		if recentRuns[i].Command.Raw == latest.Command.Raw {
+			return recentRuns[i]
+		}
+	}
+	return nil
+}
+
+// handleExplainCommand explains a command or concept. Command lines get a
+// flag-by-flag breakdown from the local documentation first, and the AI's
+// explanation follows unless --no-ai is given.
+func handleExplainCommand(args []string, spinner *ui.Spinner) {
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	noAI, rest := takeFlag(args, "--no-ai")
+	flags, args, err := parseAIFlags(rest)
+	if err != nil || len(args) == 0 {
+		fmt.Println(errorColor("Usage:"), "he [--no-ai] [--show-redacted] [--no-cache|--refresh] <your query>")
+		return
+	}
+
+	query := strings.Join(args, " ")
+	settings := config.GetConfig().ManPages
+
+	brokenDown := false
+	if settings.Breakdown && manpage.LooksLikeCommand(query) {
+		spinner.Start(color.New(color.FgCyan).Sprint("📖 Reading the manual..."))
+		annotations := manpage.Breakdown(query)
+		spinner.Stop()
+
+		fmt.Println(headerColor("🔍 Breakdown:"))
+		printBreakdown(query, annotations)
+		brokenDown = true
+	}
+
+	if noAI {
+		if !brokenDown {
+			fmt.Println(errorColor("Error:"), "--no-ai needs a command line to break down")
+		}
+		return
+	}
+
+	ctx, info := aiContext(flags, spinner)
+	spinner.Start(color.New(color.FgCyan).Sprint("✨ Getting explanation..."))
+	var pages []manpage.Page
+	if settings.Enabled {
+		pages = manpage.Lookup(query, settings.Budget)
+	}
+	result, err := ai.ExplainCommand(ctx, query, pages)
+	spinner.Stop()
+	printCallInfo(info)
+
+	fmt.Println(headerColor("📚 Explanation:"))
+
+	if err != nil {
+		printAIError("Error getting explanation:", err)
+
+		// Without the model, the local documentation is still useful
+		if len(pages) > 0 && !brokenDown {
+			fmt.Println(headerColor("📖 From the local documentation:"))
+			printBox(manpage.FormatAll(pages))
+		}
+		return
+	}
+
+	// Print the explanation in a box
+	printBox(result)
+
+	// For explanations, we might want to copy them as well
+	if err := clipboard.Write(result); err == nil {
+		fmt.Println(successColor("✓ Explanation copied to clipboard"))
+	}
+}
+
+// handleExplainOutput explains the output of the last command, or how it
+// changed since the previous run with --diff
+func handleExplainOutput(args []string, spinner *ui.Spinner) error {
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+
+	diff, rest := takeFlag(args, "--diff")
+	flags, question, err := parseAIFlags(rest)
+	if err != nil {
+		return err
+	}
+
+	if !config.GetConfig().Capture.Enabled {
+		return errors.New("output capture is off; turn it on with `config set capture.enabled true`")
+	}
+	if len(recentRuns) == 0 {
+		return errors.New("no command output captured yet in this session")
+	}
+	latest := recentRuns[len(recentRuns)-1]
+
+	var previous *terminal.CommandLog
+	if diff {
+		if previous = previousRun(); previous == nil {
+			return fmt.Errorf("no earlier run of %q in this session to compare with", latest.Command.Raw)
+		}
+	}
+
+	ctx, info := aiContext(flags, spinner)
+	spinner.Start(color.New(color.FgCyan).Sprint("✨ Reading the output..."))
+	result, err := explainRun(ctx, previous, latest, strings.Join(question, " "))
+	spinner.Stop()
+	printCallInfo(info)
+
+	if diff {
+		fmt.Println(headerColor("🔎 Changes in:"), latest.Command.Raw)
+	} else {
+		fmt.Println(headerColor("🔎 Output of:"), latest.Command.Raw)
+	}
+
+	if err != nil {
+		printAIError("Error explaining output:", err)
+		return nil
+	}
+	printBox(result)
+	return nil
+}
+
+// explainRun asks for an explanation of one run, or of the change between
+// two runs when previous is set
+func explainRun(ctx context.Context, previous, latest *terminal.CommandLog, question string) (string, error) {
+	if previous != nil {
+		return ai.ExplainOutputDiff(ctx, toAILog(previous), toAILog(latest), question)
+	}
+	return ai.ExplainOutput(ctx, toAILog(latest), question)
+}
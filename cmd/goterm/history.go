@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// Limits for history search and describe
+const (
+	historySearchResults = 10
+	defaultDescribeCount = 50
+	describeBatch        = 25 // commands per describe request
+)
+
+// handleHistoryCommand shows, searches or describes the command history
+func handleHistoryCommand(args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) error {
+	if len(args) == 0 {
+		fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint("📜 Command History:"))
+		history.Show()
+		return nil
+	}
+
+	switch args[0] {
+	case "search", "s":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: history search <words>, e.g. history search docker volume mount last week")
+		}
+		searchHistory(strings.Join(args[1:], " "), line, history, spinner)
+		return nil
+
+	case "describe":
+		return describeHistory(args[1:], history, spinner)
+
+	default:
+		return fmt.Errorf("unknown history subcommand: %s (use search or describe)", args[0])
+	}
+}
+
+// searchHistory ranks history against a query and offers to run, edit or
+// copy the command picked
+func searchHistory(query string, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	words, since := terminal.SplitTimePhrase(query, time.Now())
+	if words == "" && since.IsZero() {
+		fmt.Println("Give some words to search for")
+		return
+	}
+
+	matches := history.Search(words, since, historySearchResults)
+	if len(matches) == 0 {
+		fmt.Printf("No commands match %q\n", query)
+		if !since.IsZero() {
+			fmt.Println(hintColor("Only commands run since GO-TERM started keeping run times can match a time"))
+		}
+		return
+	}
+
+	items := make([]ui.PickerItem, len(matches))
+	for i, m := range matches {
+		items[i] = ui.PickerItem{Title: m.Command, Detail: m.Description, Note: matchNote(m)}
+	}
+
+	title := fmt.Sprintf("🔎 %d matches for %q:", len(matches), query)
+	index, err := ui.Pick(title, items)
+	if err == ui.ErrCancelled {
+		return
+	} else if err != nil {
+		// No interactive terminal: list the matches
+		fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint(title))
+		for i, item := range items {
+			fmt.Printf("  %2d. %s\n", i+1, item.Title)
+			if item.Detail != "" {
+				fmt.Println("      " + item.Detail)
+			}
+			fmt.Println(hintColor("      " + item.Note))
+		}
+		return
+	}
+
+	chosen := matches[index].Command
+	fmt.Println(color.New(color.FgHiCyan, color.Bold).Sprint("❯ ") + chosen)
+	reviewCommand(chosen, "", line, history, spinner)
+}
+
+// matchNote describes where, when and how often a command ran
+func matchNote(m terminal.HistoryMatch) string {
+	var parts []string
+	if len(m.Dirs) > 0 {
+		parts = append(parts, m.Dirs[0])
+	}
+	if !m.Last.IsZero() {
+		parts = append(parts, m.Last.Format("2006-01-02 15:04"))
+	}
+	if m.Uses > 1 {
+		parts = append(parts, fmt.Sprintf("%d runs", m.Uses))
+	}
+	if m.Score > 0 {
+		parts = append(parts, fmt.Sprintf("score %.2f", m.Score))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// describeHistory has the AI describe commands that have no description,
+// so searches in everyday words find them
+func describeHistory(args []string, history *terminal.History, spinner *ui.Spinner) error {
+	flags, args, err := parseAIFlags(args)
+	if err != nil || len(args) > 1 {
+		return fmt.Errorf("usage: history describe [--show-redacted] [--no-cache|--refresh] [count]")
+	}
+	count := defaultDescribeCount
+	if len(args) == 1 {
+		if count, err = strconv.Atoi(args[0]); err != nil || count < 1 {
+			return fmt.Errorf("count must be a positive number")
+		}
+	}
+
+	commands := history.Undescribed(count)
+	if len(commands) == 0 {
+		fmt.Println("Every command in history already has a description")
+		return nil
+	}
+
+	described := 0
+	for start := 0; start < len(commands); start += describeBatch {
+		batch := commands[start:min(start+describeBatch, len(commands))]
+
+		ctx, info := aiContext(flags, spinner)
+		spinner.Start(color.New(color.FgCyan).Sprintf("✨ Describing commands %d-%d of %d...", start+1, start+len(batch), len(commands)))
+		descriptions, err := ai.DescribeCommands(ctx, batch)
+		spinner.Stop()
+		printCallInfo(info)
+
+		if err != nil {
+			printAIError("Error describing commands:", err)
+			break
+		}
+		if err := history.Describe(descriptions); err != nil {
+			return err
+		}
+		described += len(descriptions)
+	}
+
+	fmt.Println(color.New(color.FgGreen, color.Bold).Sprintf("✓ Described %d of %d commands", described, len(commands)))
+	return nil
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"github/0PrashantYadav0/GO-TERM/internal/ai"
 	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/environment"
+	"github/0PrashantYadav0/GO-TERM/internal/policy"
 	"github/0PrashantYadav0/GO-TERM/internal/terminal"
 	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
 	"github/0PrashantYadav0/GO-TERM/pkg/utils"
 	"io"
 	"os"
@@ -20,6 +22,11 @@ import (
 )
 
 func main() {
+	// goterm eval runs prompt evaluations instead of the interactive shell
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(runEval(os.Args[2:]))
+	}
+
 	// Clear console
 	fmt.Print("\033[H\033[2J")
 
@@ -38,6 +45,8 @@ func main() {
 
 	// Initialize history
 	history := terminal.NewHistory()
+	environment.Default.SetHistorySource(history.Last)
+	aliases = terminal.NewAliasManager(config.GetConfigDir())
 
 	// Initialize liner for input with arrow key support
 	line := liner.NewLiner()
@@ -47,7 +56,7 @@ func main() {
 	line.SetCtrlCAborts(false)
 	line.SetTabCompletionStyle(liner.TabCircular)
 
-	// Load command history to liner
+	// Load command history to liner, which still serves the edit prompts
 	if f, err := os.Open(terminal.GetHistoryFilePath()); err == nil {
 		line.ReadHistory(f)
 		f.Close()
@@ -55,19 +64,16 @@ func main() {
 
 	spinner := ui.NewSpinner()
 
-	// Configure liner for tab completion
-	line.SetCompleter(func(line string) (c []string) {
-		suggestion := getCommandSuggestion(line)
-		if suggestion != "" {
-			c = append(c, suggestion)
-		}
-		return
-	})
+	// The main prompt uses our own editor for history, completion and Ctrl+G
+	setupLineEditor(history)
 
 	for {
 		// Display colorful divider before each prompt
 		printDivider()
 
+		// Offer a fix for the last command if one is being prefetched
+		offer := armFix()
+
 		// Display prompt - use a simple prompt that won't cause issues
 		rawPrompt := ai.FormatPrompt()
 		// Strip any ANSI color codes or other special characters
@@ -79,120 +85,99 @@ func main() {
 		}
 
 		// Check for clipboard suggestions (non-blocking)
-		var suggestion string
 		select {
-		case suggestion = <-suggestions:
-			// Got a suggestion
+		case clipboardSuggestion = <-suggestions:
+			// Show it above the prompt; Tab on an empty line accepts it
+			fmt.Println(color.New(color.FgHiMagenta).Sprint(clipboardSuggestion) +
+				color.New(color.FgHiBlack).Sprint("  (Tab to use)"))
 		default:
 			// No suggestion available
 		}
 
-		// Read command with liner (supports arrow keys)
-		var input string
-		var err error
-
-		// Use try-catch pattern for liner prompt
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Println("Recovered from liner panic, using simple prompt")
-					fmt.Print(safePrompt)
-					reader := bufio.NewReader(os.Stdin)
-					inputBytes, _ := reader.ReadBytes('\n')
-					input = strings.TrimSpace(string(inputBytes))
-					err = nil
-				}
-			}()
-
-			// Setup history suggestion completer
-			line.SetCompleter(func(line string) (candidates []string) {
-				if line == "" {
-					return nil
-				}
-
-				// Find matching history command
-				suggestion := history.GetRecent(line)
-				if suggestion != "" && suggestion != line && strings.HasPrefix(suggestion, line) {
-					return []string{suggestion}
-				}
-				return nil
-			})
-
-			if suggestion != "" {
-				// If we have a clipboard suggestion, show it separately
-				suggestedText := color.New(color.FgHiMagenta).Sprint(suggestion)
-				fmt.Print("\r" + safePrompt + suggestedText)
-				input, err = line.Prompt("")
-				fmt.Print("\r\033[K") // Clear the line
-			} else {
-				// Simple prompt with tab completion from history
-				input, err = line.Prompt(safePrompt)
-			}
-		}()
+		input, err := lineEditor.Prompt(safePrompt, "")
+		settleFix(offer, line, history, spinner)
+		if fixAccepted {
+			continue
+		}
+
+		if err != nil && err != io.EOF && err != ui.ErrInterrupted {
+			// Fall back to simple line input rather than leaving the shell
+			fmt.Println(color.New(color.FgHiRed).Sprintf("Simple mode activated due to error: %s", err))
+			input, err = line.Prompt(safePrompt)
+		}
 
 		if err == io.EOF {
 			fmt.Println("\nExiting GO-TERM...")
 			break
+		} else if err == ui.ErrInterrupted {
+			continue // Allow Ctrl+C to just cancel the current input
 		} else if err != nil {
-			if err.Error() == "Interrupted" {
-				continue // Allow Ctrl+C to just cancel the current input
-			}
-			errorText := color.New(color.FgHiRed).Sprintf("Simple mode activated due to error: %s", err)
-			fmt.Println(errorText)
-
-			// Fall back to simple input method
-			fmt.Print(safePrompt)
-			reader := bufio.NewReader(os.Stdin)
-			inputBytes, _ := reader.ReadBytes('\n')
-			input = strings.TrimSpace(string(inputBytes))
+			fmt.Println(color.New(color.FgHiRed).Sprintf("Error reading input: %s", err))
+			break
 		}
 
+		input = strings.TrimSpace(input)
 		if input == "" {
 			continue
 		}
 
-		// Add to liner history
-		line.AppendHistory(input)
-
-		// Save history to file periodically
-		if f, err := os.Create(terminal.GetHistoryFilePath()); err == nil {
-			line.WriteHistory(f)
-			f.Close()
+		// A pasted block keeps its newlines; running it as one line would
+		// run something other than what was pasted
+		if strings.ContainsAny(input, "\n\r") {
+			fmt.Println(color.New(color.FgYellow).Sprint("Multi-line input is not run. Run the commands one at a time, or save them as a script."))
+			continue
 		}
 
+		// Add to prompt history and save it
+		appendHistory(line, input)
+
 		// Handle exit command
 		if input == "exit" {
 			printExitMessage()
 			return
 		}
 
+		// History keeps the alias as typed; the expansion is what runs
+		command := aliases.ExpandCommand(input)
+		if command != input {
+			fmt.Println(color.New(color.FgHiBlack).Sprint("↳ " + command))
+		}
+
 		// Handle special commands
-		if handled := handleSpecialCommands(input, history, spinner); handled {
+		if handled := handleSpecialCommands(command, history, spinner, line); handled {
 			continue
 		}
 
 		// Add to our custom history
 		history.Add(input)
 
-		// Execute regular command with animated loading screen
-		cmdDone := make(chan bool)
+		executeInput(command, spinner)
+	}
+}
+
+// executeInput runs a regular command with an animated loading screen
+func executeInput(input string, spinner *ui.Spinner) {
+	var entry *terminal.CommandLog
+	cmdDone := make(chan bool)
+
+	// Start spinner in a goroutine
+	spinner.Start(color.New(color.FgHiBlue, color.Bold).Sprint("⚡ Executing: ") +
+		color.New(color.FgHiCyan).Sprint(input))
 
-		// Start spinner in a goroutine
-		spinner.Start(color.New(color.FgHiBlue, color.Bold).Sprint("⚡ Executing: ") +
-			color.New(color.FgHiCyan).Sprint(input))
+	// Execute the command in a goroutine
+	go func() {
+		entry = terminal.ExecuteCommand(input)
+		cmdDone <- true
+	}()
 
-		// Execute the command in a goroutine
-		go func() {
-			terminal.ExecuteCommand(input)
-			cmdDone <- true
-		}()
+	// Wait for command to complete
+	<-cmdDone
 
-		// Wait for command to complete
-		<-cmdDone
+	// Stop the spinner
+	spinner.Stop()
 
-		// Stop the spinner
-		spinner.Stop()
-	}
+	recordRun(entry)
+	noteResult(entry)
 }
 
 func printEnhancedBanner() {
@@ -274,10 +259,23 @@ func printEnhancedBanner() {
 
 	cmds := []string{
 		"  • " + cyan("hm") + " - " + green("Get AI help for fixing the last error"),
-		"  • " + cyan("hp <query>") + " - " + green("Ask AI for a command"),
+		"  • " + cyan("hp [-n count] <query>") + " - " + green("Ask AI for a command"),
+		"  • " + cyan("Ctrl+G") + " - " + green("Turn the text at the prompt into a command (Ctrl+Z to undo)"),
 		"  • " + cyan("he <query>") + " - " + green("Get AI explanation for a command"),
+		"  • " + cyan("hx [--diff] [question]") + " - " + green("Explain the output of the last command"),
+		"  • " + cyan("htr --to linux|macos|powershell|fish <command>") + " - " + green("Translate a command for another platform or shell"),
+		"  • " + cyan("hscript [--goterm] <description> -o <file>") + " - " + green("Write a checked script for a multi-step task"),
+		"  • " + cyan("gcm [--style conventional|gitmoji|plain]") + " - " + green("Write a commit message for the staged changes"),
 		"  • " + cyan("chat <question>") + " - " + green("Get a brief answer to your question"),
+		"  • " + cyan("chat") + " - " + green("Start a multi-turn chat session"),
 		"  • " + cyan("history") + " - " + green("Show command history"),
+		"  • " + cyan("history search <words>") + " - " + green("Find a past command, e.g. docker volume mount last week"),
+		"  • " + cyan("alias list|add|remove|suggest") + " - " + green("Shorten the commands you repeat"),
+		"  • " + cyan("agent <goal>") + " - " + green("Work toward a goal step by step, with approval"),
+		"  • " + cyan("config list|get|set") + " - " + green("View or change settings"),
+		"  • " + cyan("prompts list|edit|reset|test|add") + " - " + green("Customize AI prompts and define your own AI commands"),
+		"  • " + cyan("ai cache stats|clear") + " - " + green("Inspect or empty the AI response cache"),
+		"  • " + cyan("ai usage [--since 7d] [--by command|model|day]") + " - " + green("Token usage and estimated cost"),
 		"  • " + cyan("exit") + " - " + green("Exit GO-TERM"),
 	}
 
@@ -331,200 +329,275 @@ func printExitMessage() {
 		fmt.Print(colorFuncs[colorIndex](string(char)))
 		time.Sleep(30 * time.Millisecond)
 	}
-	fmt.Println("\n")
+	fmt.Print("\n\n")
 }
 
-func handleSpecialCommands(input string, history *terminal.History, spinner *ui.Spinner) bool {
+func handleSpecialCommands(input string, history *terminal.History, spinner *ui.Spinner, line *liner.State) bool {
 	parts := strings.Fields(input)
 	if len(parts) == 0 {
 		return false
 	}
 
 	cmd := parts[0]
-	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
 	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
 	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
 
 	switch cmd {
 	case "history":
-		fmt.Println(headerColor("📜 Command History:"))
-		history.Show()
+		if err := handleHistoryCommand(parts[1:], line, history, spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
 		return true
 
 	case "cd":
 		terminal.ChangeDirectory(input)
 		return true
 
+	case "alias", "a":
+		if err := handleAliasCommand(input, parts[1:], line, history, spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
+
+	case "config":
+		// Quoted values such as a credential command keep their spaces
+		args, err := terminal.SplitArgs(input)
+		if err == nil {
+			err = handleConfigCommand(args[1:])
+		}
+		if err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
+
+	case "ai":
+		if err := handleAICommand(parts[1:]); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
+
+	case "prompts":
+		if err := handlePromptsCommand(parts[1:]); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
+
+	case "agent":
+		if err := handleAgentCommand(parts[1:], line, history, spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
+
 	case "cat":
 		terminal.CatFile(input)
 		return true
 
 	case "hm": // Help Me (fix last error)
+		flags, _, err := parseAIFlags(parts[1:])
+		if err != nil {
+			fmt.Println(errorColor("Usage:"), "hm [--show-redacted] [--no-cache|--refresh]")
+			return true
+		}
+
+		ctx, info := aiContext(flags, spinner)
 		spinner.Start(color.New(color.FgCyan).Sprint("✨ Processing last error..."))
-		result, err := ai.GenerateCommandForHm()
+		var result *ai.Suggestion
+		if lastFailure != nil {
+			// Use this session's failure rather than re-reading the error log
+			result, err = ai.GenerateFix(ctx, toAILog(lastFailure))
+		} else {
+			result, err = ai.GenerateCommandForHm(ctx)
+		}
 		spinner.Stop()
+		printCallInfo(info)
 
 		if err != nil {
-			fmt.Println(errorColor("Error getting AI help:"), err)
-		} else if result == "3d8a19a704" {
-			fmt.Println(errorColor("Sorry, I couldn't help with that error."))
+			printAIError("Error getting AI help:", err)
 		} else {
-			fmt.Println(headerColor("🚀 Try:"), color.New(color.FgHiCyan, color.Bold).Sprint(result))
-
-			// Copy the command to clipboard
-			if err := clipboard.Write(result); err == nil {
-				fmt.Println(successColor("✓ Command copied to clipboard"))
-			}
+			reviewSuggestions([]*ai.Suggestion{result}, line, history, spinner)
 		}
 		return true
 
 	case "hp": // Help Please (get command suggestion)
-		if len(parts) < 2 {
-			fmt.Println(errorColor("Usage:"), "hp <your query>")
+		flags, args, err := parseAIFlags(parts[1:])
+		if err != nil || len(args) == 0 {
+			fmt.Println(errorColor("Usage:"), "hp [-n count] [--show-redacted] [--no-cache|--refresh] <your query>")
 			return true
 		}
 
-		query := strings.Join(parts[1:], " ")
+		query := strings.Join(args, " ")
+		ctx, info := aiContext(flags, spinner)
 		spinner.Start(color.New(color.FgCyan).Sprint("✨ Processing your query..."))
-		result, err := ai.GenerateCommandForHp(query)
+		results, err := ai.GenerateCandidatesForHp(ctx, query, flags.candidates)
 		spinner.Stop()
+		printCallInfo(info)
 
 		if err != nil {
-			fmt.Println(errorColor("Error getting AI help:"), err)
-		} else if result == "3d8a19a704" {
-			fmt.Println(errorColor("Sorry, I couldn't generate a command for that query."))
+			printAIError("Error getting AI help:", err)
 		} else {
-			fmt.Println(headerColor("🚀 Try:"), color.New(color.FgHiCyan, color.Bold).Sprint(result))
-
-			// Copy the command to clipboard
-			if err := clipboard.Write(result); err == nil {
-				fmt.Println(successColor("✓ Command copied to clipboard"))
-			}
+			reviewSuggestions(results, line, history, spinner)
 		}
 		return true
 
 	case "he": // Help Explain
-		if len(parts) < 2 {
-			fmt.Println(errorColor("Usage:"), "he <your query>")
-			return true
-		}
-
-		query := strings.Join(parts[1:], " ")
-		spinner.Start(color.New(color.FgCyan).Sprint("✨ Getting explanation..."))
-		result, err := ai.ExplainCommand(query)
-		spinner.Stop()
+		handleExplainCommand(parts[1:], spinner)
+		return true
 
-		fmt.Println(headerColor("📚 Explanation:"))
+	case "hx": // Help eXplain output
+		if err := handleExplainOutput(parts[1:], spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
 
-		if err != nil {
-			fmt.Println(errorColor("Error getting explanation:"), err)
-		} else if result == "3d8a19a704" {
-			fmt.Println(errorColor("Sorry, I couldn't provide an explanation."))
-		} else {
-			// Print the explanation in a box
-			width := utils.GetTerminalWidth()
-			boxWidth := width - 4
-
-			// Top border
-			fmt.Println(color.New(color.FgHiBlack).Sprint("┌" + strings.Repeat("─", boxWidth) + "┐"))
-
-			// Split explanation into lines and print with padding
-			explLines := strings.Split(result, "\n")
-			for _, line := range explLines {
-				// Handle line wrapping for long lines
-				for len(line) > boxWidth-4 {
-					fmt.Print(color.New(color.FgHiBlack).Sprint("│ "))
-					fmt.Print(color.New(color.FgHiWhite).Sprint(line[:boxWidth-4]))
-					fmt.Println(color.New(color.FgHiBlack).Sprint(" │"))
-					line = line[boxWidth-4:]
-				}
-				fmt.Print(color.New(color.FgHiBlack).Sprint("│ "))
-				fmt.Print(color.New(color.FgHiWhite).Sprint(line))
-				padding := boxWidth - 2 - len(line)
-				fmt.Print(strings.Repeat(" ", padding))
-				fmt.Println(color.New(color.FgHiBlack).Sprint(" │"))
-			}
+	case "htr": // Help TRanslate
+		if err := handleTranslateCommand(input, parts[1:], line, history, spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
 
-			// Bottom border
-			fmt.Println(color.New(color.FgHiBlack).Sprint("└" + strings.Repeat("─", boxWidth) + "┘"))
+	case "hscript": // Help SCRIPT
+		if err := handleScriptCommand(parts[1:], line, spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
+		}
+		return true
 
-			// For explanations, we might want to copy them as well
-			if err := clipboard.Write(result); err == nil {
-				fmt.Println(successColor("✓ Explanation copied to clipboard"))
-			}
+	case "gcm": // Git Commit Message
+		if err := handleCommitCommand(parts[1:], line, history, spinner); err != nil {
+			fmt.Println(errorColor("Error:"), err)
 		}
 		return true
 
 	case "chat": // Chat with AI
-		if len(parts) < 2 {
-			fmt.Println(errorColor("Usage:"), "chat <your question>")
+		flags, args, err := parseAIFlags(parts[1:])
+		if err != nil {
+			fmt.Println(errorColor("Usage:"), "chat [--show-redacted] [--no-cache|--refresh] [@file[:10-80]|@last ...] [question]")
 			return true
 		}
 
-		question := strings.Join(parts[1:], " ")
+		if len(args) == 0 {
+			// No question given: start an interactive chat session
+			ctx, _ := aiContext(flags, spinner)
+			runChatSession(ctx, line, spinner)
+			return true
+		}
+
+		question, attachments, skipped, err := parseAttachments(strings.Join(args, " "))
+		if err != nil {
+			fmt.Println(errorColor("Error:"), err)
+			return true
+		}
+		printAttachments(attachments, skipped)
+
+		ctx, info := aiContext(flags, spinner)
 		spinner.Start(color.New(color.FgCyan).Sprint("✨ Thinking..."))
-		result, err := ai.ChatWithAI(question)
+		result, err := ai.ChatWithAI(ctx, question, attachments)
 		spinner.Stop()
+		printCallInfo(info)
 
 		fmt.Println(headerColor("💬 Answer:"))
 
 		if err != nil {
-			fmt.Println(errorColor("Error getting answer:"), err)
-		} else if result == "3d8a19a704" {
-			fmt.Println(errorColor("Sorry, I couldn't answer that question."))
+			printAIError("Error getting answer:", err)
 		} else {
 			// Print the answer in a box
-			width := utils.GetTerminalWidth()
-			boxWidth := width - 4
-
-			// Top border
-			fmt.Println(color.New(color.FgHiBlack).Sprint("┌" + strings.Repeat("─", boxWidth) + "┐"))
-
-			// Split answer into lines and print with padding
-			answerLines := strings.Split(result, "\n")
-			for _, line := range answerLines {
-				// Handle line wrapping for long lines
-				for len(line) > boxWidth-4 {
-					fmt.Print(color.New(color.FgHiBlack).Sprint("│ "))
-					fmt.Print(color.New(color.FgHiWhite).Sprint(line[:boxWidth-4]))
-					fmt.Println(color.New(color.FgHiBlack).Sprint(" │"))
-					line = line[boxWidth-4:]
-				}
-				fmt.Print(color.New(color.FgHiBlack).Sprint("│ "))
-				fmt.Print(color.New(color.FgHiWhite).Sprint(line))
-				padding := boxWidth - 2 - len(line)
-				fmt.Print(strings.Repeat(" ", padding))
-				fmt.Println(color.New(color.FgHiBlack).Sprint(" │"))
-			}
-
-			// Bottom border
-			fmt.Println(color.New(color.FgHiBlack).Sprint("└" + strings.Repeat("─", boxWidth) + "┘"))
+			printBox(result)
 
 			// Note: Not copying to clipboard as requested
 		}
 		return true
 	}
 
+	// User-defined AI commands from prompts add
+	if command, ok := config.GetConfig().Prompts.Commands[cmd]; ok {
+		runCustomCommand(cmd, command, parts[1:], line, history, spinner)
+		return true
+	}
+
 	return false
 }
 
+// printSuggestion shows a suggested command with its risk and rationale
+func printSuggestion(suggestion *ai.Suggestion) {
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Println(headerColor("🚀 Try:"), color.New(color.FgHiCyan, color.Bold).Sprint(suggestion.Command))
+
+	if suggestion.Explanation != "" {
+		fmt.Println("   " + color.New(color.FgHiWhite).Sprint(suggestion.Explanation))
+	}
+
+	assessment := policy.Check(suggestion.Command, suggestion.RiskLevel)
+	riskColor := color.New(color.FgGreen, color.Bold)
+	switch assessment.Risk {
+	case policy.RiskMedium:
+		riskColor = color.New(color.FgYellow, color.Bold)
+	case policy.RiskHigh:
+		riskColor = color.New(color.FgRed, color.Bold)
+	}
+
+	details := fmt.Sprintf("confidence %.0f%%", suggestion.Confidence*100)
+	if suggestion.RequiresSudo {
+		details += ", needs sudo"
+	}
+	fmt.Println("   " + riskColor.Sprintf("● %s risk", assessment.Risk) + " " + hintColor("("+details+")"))
+
+	for _, reason := range assessment.Reasons {
+		fmt.Println("   " + riskColor.Sprint("⚠ ") + hintColor(reason))
+	}
+
+	if len(suggestion.Missing) > 0 {
+		fmt.Println("   " + color.New(color.FgYellow).Sprint("⚠ not installed: ") + hintColor(strings.Join(suggestion.Missing, ", ")))
+	}
+
+	for _, alt := range suggestion.Alternatives {
+		fmt.Println("   " + hintColor("or: ") + color.New(color.FgCyan).Sprint(alt))
+	}
+}
+
+// printBox prints text inside a bordered box sized to the terminal
+func printBox(text string) {
+	width := utils.GetTerminalWidth()
+	boxWidth := width - 4
+
+	// Top border
+	fmt.Println(color.New(color.FgHiBlack).Sprint("┌" + strings.Repeat("─", boxWidth) + "┐"))
+
+	// Split text into lines and print with padding
+	textLines := strings.Split(text, "\n")
+	for _, line := range textLines {
+		// Handle line wrapping for long lines
+		for len(line) > boxWidth-4 {
+			fmt.Print(color.New(color.FgHiBlack).Sprint("│ "))
+			fmt.Print(color.New(color.FgHiWhite).Sprint(line[:boxWidth-4]))
+			fmt.Println(color.New(color.FgHiBlack).Sprint(" │"))
+			line = line[boxWidth-4:]
+		}
+		fmt.Print(color.New(color.FgHiBlack).Sprint("│ "))
+		fmt.Print(color.New(color.FgHiWhite).Sprint(line))
+		padding := boxWidth - 2 - len(line)
+		fmt.Print(strings.Repeat(" ", padding))
+		fmt.Println(color.New(color.FgHiBlack).Sprint(" │"))
+	}
+
+	// Bottom border
+	fmt.Println(color.New(color.FgHiBlack).Sprint("└" + strings.Repeat("─", boxWidth) + "┘"))
+}
+
 func setupSignalHandler() {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-c
+		for sig := range c {
+			// Ctrl-C during an agent step stops just that step
+			if cancel := runningStep.Load(); cancel != nil && sig == os.Interrupt {
+				(*cancel)()
+				continue
+			}
+			break
+		}
 		fmt.Println("\n" + color.New(color.FgYellow, color.Bold).Sprint("Exiting GO-TERM..."))
 		os.Exit(0)
 	}()
 }
-
-// Function to get command suggestion
-func getCommandSuggestion(input string) string {
-	// Use AI to generate command suggestion
-	suggestion, err := ai.GenerateCommandForHp(input)
-	if err != nil || suggestion == "3d8a19a704" {
-		return ""
-	}
-	return suggestion
-}
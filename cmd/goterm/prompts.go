@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// builtinCommands are handled by GO-TERM itself, so custom commands can't
+// take their names
+var builtinCommands = map[string]bool{
+	"history": true, "cd": true, "config": true, "ai": true, "agent": true, "cat": true,
+	"hm": true, "hp": true, "he": true, "hx": true, "htr": true, "hscript": true, "gcm": true, "chat": true,
+	"prompts": true, "alias": true, "a": true, "exit": true,
+}
+
+// outputModes are the ways a custom command can show its answer
+var outputModes = []string{ai.OutputText, ai.OutputCopy, ai.OutputCommand}
+
+// handlePromptsCommand lists, edits, resets and previews prompt templates,
+// and adds or removes custom AI commands
+func handlePromptsCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("prompts command requires a subcommand (list, edit, reset, test, add, remove)")
+	}
+
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+
+	switch args[0] {
+	case "list", "ls":
+		return listPrompts()
+
+	case "edit":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: prompts edit <name>")
+		}
+		return editPrompt(args[1])
+
+	case "reset":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: prompts reset <name>")
+		}
+		if !prompts.Builtin(args[1]) {
+			return fmt.Errorf("no built-in prompt named %q (custom commands are deleted with prompts remove)", args[1])
+		}
+		removed, err := prompts.Reset(args[1])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			fmt.Println("The", args[1], "prompt is already the built-in one")
+			return nil
+		}
+		fmt.Println(successColor("✓ Restored the built-in"), args[1], successColor("prompt"))
+
+	case "remove", "rm":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: prompts remove <name>")
+		}
+		if _, ok := config.GetConfig().Prompts.Commands[args[1]]; !ok {
+			return fmt.Errorf("no custom command named %q", args[1])
+		}
+		delete(config.GetConfig().Prompts.Commands, args[1])
+		if err := config.Save(); err != nil {
+			return err
+		}
+		if _, err := prompts.Reset(args[1]); err != nil {
+			return err
+		}
+		fmt.Println(successColor("✓ Removed the custom command"), args[1])
+
+	case "test":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: prompts test <name> [input]")
+		}
+		prompt, err := ai.PreviewPrompt(args[1], strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint("🧪 Rendered prompt:"))
+		printBox(strings.TrimSpace(prompt))
+		fmt.Println(color.New(color.FgHiBlack).Sprintf("%d characters; nothing was sent", len(prompt)))
+
+	case "add":
+		return addCustomCommand(args[1:])
+
+	default:
+		return fmt.Errorf("unknown prompts subcommand: %s", args[0])
+	}
+
+	return nil
+}
+
+// listPrompts shows every template, whether it was edited, and the custom
+// commands using them
+func listPrompts() error {
+	keyColor := color.New(color.FgCyan).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	editedColor := color.New(color.FgYellow).SprintFunc()
+
+	infos, err := prompts.List()
+	if err != nil {
+		return err
+	}
+	commands := config.GetConfig().Prompts.Commands
+
+	// Custom commands whose template hasn't been written yet still count
+	listed := map[string]bool{}
+	for _, info := range infos {
+		listed[info.Name] = true
+	}
+	for name := range commands {
+		if !listed[name] {
+			infos = append(infos, prompts.Info{Name: name, Path: prompts.Path(name)})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	for _, info := range infos {
+		status := "built-in"
+		switch {
+		case info.Builtin && info.Edited:
+			status = editedColor("edited")
+		case !info.Builtin:
+			if command, ok := commands[info.Name]; ok {
+				status = "custom command, " + command.Output + " output"
+				if command.Description != "" {
+					status += ": " + command.Description
+				}
+				if !info.Edited {
+					status += editedColor(" (no template yet)")
+				}
+			} else {
+				status = "template only"
+			}
+		}
+		fmt.Printf("  %s %s\n", keyColor(fmt.Sprintf("%-18s", info.Name)), status)
+	}
+	fmt.Println(hintColor("Templates live in " + prompts.Dir()))
+	return nil
+}
+
+// editPrompt opens a template in $EDITOR, starting from the built-in text,
+// and saves it once it parses
+func editPrompt(name string) error {
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+
+	text, _, err := prompts.Source(name)
+	if err != nil {
+		if _, ok := config.GetConfig().Prompts.Commands[name]; !ok {
+			return fmt.Errorf("no prompt named %q (add a custom command with prompts add %s)", name, name)
+		}
+		text = prompts.Starter(name)
+	}
+
+	original := text
+	for {
+		edited, err := ui.EditText(text, name+"-*.tmpl")
+		if err != nil {
+			return fmt.Errorf("running editor: %w", err)
+		}
+		if edited == original {
+			fmt.Println("No changes made")
+			return nil
+		}
+
+		err = prompts.Save(name, edited)
+		if err == nil {
+			fmt.Println(successColor("✓ Saved"), prompts.Path(name))
+			return nil
+		}
+
+		fmt.Println(errorColor("Error:"), err)
+		fmt.Print("Edit again? [Y/n] ")
+		key, keyErr := ui.ReadKey()
+		fmt.Println()
+		if keyErr != nil || key == "n" || key == "N" {
+			return errors.New("template not saved")
+		}
+		text = edited
+	}
+}
+
+// addCustomCommand registers a new AI command and opens its template
+func addCustomCommand(args []string) error {
+	output := ai.OutputText
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--output" {
+			if i+1 >= len(args) {
+				return fmt.Errorf("--output requires one of %s", strings.Join(outputModes, ", "))
+			}
+			i++
+			output = args[i]
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if len(rest) == 0 {
+		return fmt.Errorf("usage: prompts add <name> [--output %s] [description]", strings.Join(outputModes, "|"))
+	}
+
+	name := rest[0]
+	switch {
+	case !prompts.ValidName(name):
+		return fmt.Errorf("command names use lowercase letters, digits and _")
+	case builtinCommands[name] || prompts.Builtin(name):
+		return fmt.Errorf("%s is a built-in command", name)
+	}
+	if _, err := exec.LookPath(name); err == nil {
+		return fmt.Errorf("%s would hide the installed %s command", name, name)
+	}
+	if !slices.Contains(outputModes, output) {
+		return fmt.Errorf("unknown output %q (use %s)", output, strings.Join(outputModes, ", "))
+	}
+
+	cfg := config.GetConfig()
+	if cfg.Prompts.Commands == nil {
+		cfg.Prompts.Commands = map[string]config.AICommand{}
+	}
+	cfg.Prompts.Commands[name] = config.AICommand{Description: strings.Join(rest[1:], " "), Output: output}
+	if err := config.Save(); err != nil {
+		return err
+	}
+	fmt.Println(color.New(color.FgGreen, color.Bold).Sprint("✓ Added"), name, "- run it as", name, "<input>")
+
+	if _, _, err := prompts.Source(name); err == nil {
+		// Keep a template that is already there, e.g. in a shared directory
+		return nil
+	}
+	if err := prompts.Save(name, prompts.Starter(name)); err != nil {
+		return err
+	}
+	return editPrompt(name)
+}
+
+// runCustomCommand runs a user-defined AI command with the text typed after it
+func runCustomCommand(name string, command config.AICommand, args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+
+	flags, args, err := parseAIFlags(args)
+	if err != nil {
+		fmt.Println(errorColor("Usage:"), name, "[--show-redacted] [--no-cache|--refresh] [input]")
+		return
+	}
+	input := strings.Join(args, " ")
+
+	ctx, info := aiContext(flags, spinner)
+	spinner.Start(color.New(color.FgCyan).Sprint("✨ Running " + name + "..."))
+
+	if command.Output == ai.OutputCommand {
+		suggestion, err := ai.SuggestCustomCommand(ctx, name, input)
+		spinner.Stop()
+		printCallInfo(info)
+		if err != nil {
+			printAIError("Error running "+name+":", err)
+			return
+		}
+		reviewSuggestions([]*ai.Suggestion{suggestion}, line, history, spinner)
+		return
+	}
+
+	result, err := ai.RunCustomCommand(ctx, name, input)
+	spinner.Stop()
+	printCallInfo(info)
+	if err != nil {
+		printAIError("Error running "+name+":", err)
+		return
+	}
+
+	printBox(result)
+	if command.Output == ai.OutputCopy {
+		if err := clipboard.Write(result); err == nil {
+			fmt.Println(successColor("✓ Copied to clipboard"))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/policy"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// reviewSuggestions lets the user pick one of the suggestions and then run,
+// edit or copy it
+func reviewSuggestions(suggestions []*ai.Suggestion, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	chosen := suggestions[0]
+
+	if len(suggestions) > 1 {
+		items := make([]ui.PickerItem, len(suggestions))
+		for i, s := range suggestions {
+			items[i] = ui.PickerItem{Title: s.Command, Detail: s.Explanation}
+			if len(s.Missing) > 0 {
+				items[i].Note = "⚠ not installed: " + strings.Join(s.Missing, ", ")
+			} else if risk := policy.Check(s.Command, s.RiskLevel).Risk; risk != policy.RiskLow {
+				items[i].Note = "● " + risk.String() + " risk"
+			}
+		}
+
+		index, err := ui.Pick("🚀 Candidates:", items)
+		if err == ui.ErrCancelled {
+			return
+		} else if err != nil {
+			// No interactive terminal: list everything and fall back to the first
+			for _, s := range suggestions[1:] {
+				chosen.Alternatives = append(chosen.Alternatives, s.Command)
+			}
+		} else {
+			chosen = suggestions[index]
+		}
+	}
+
+	printSuggestion(chosen)
+	reviewCommand(chosen.Command, chosen.RiskLevel, line, history, spinner)
+}
+
+// reviewCommand offers to run, edit or copy a command
+func reviewCommand(command string, declaredRisk string, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	successColor := color.New(color.FgGreen, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	keyColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+
+	fmt.Print(keyColor("[r]") + "un  " + keyColor("[e]") + "dit  " + keyColor("[c]") + "opy  " + hintColor("any other key to skip "))
+	key, err := ui.ReadKey()
+	fmt.Println()
+
+	if err != nil {
+		// Not an interactive terminal: keep the old copy-only behaviour
+		if err := clipboard.Write(command); err == nil {
+			fmt.Println(successColor("✓ Command copied to clipboard"))
+		}
+		return
+	}
+
+	switch key {
+	case "r", "R":
+		runReviewedCommand(command, declaredRisk, line, history, spinner)
+
+	case "e", "E":
+		edited, err := line.PromptWithSuggestion(color.New(color.FgHiMagenta, color.Bold).Sprint("edit ❯ "), command, -1)
+		edited = strings.TrimSpace(edited)
+		if err != nil || edited == "" {
+			return
+		}
+		// The user has taken ownership of the command, so drop the model's risk claim
+		if edited != command {
+			declaredRisk = ""
+		}
+		runReviewedCommand(edited, declaredRisk, line, history, spinner)
+
+	case "c", "C":
+		if err := clipboard.Write(command); err == nil {
+			fmt.Println(successColor("✓ Command copied to clipboard"))
+		} else {
+			fmt.Println(color.New(color.FgRed, color.Bold).Sprint("Could not copy to clipboard:"), err)
+		}
+	}
+}
+
+// runReviewedCommand runs a command after the guard has had its say
+func runReviewedCommand(command string, declaredRisk string, line *liner.State, history *terminal.History, spinner *ui.Spinner) {
+	errorColor := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	assessment := policy.Check(command, declaredRisk)
+	if assessment.Risk == policy.RiskHigh {
+		fmt.Println(errorColor("⚠ This command is high risk:"), strings.Join(assessment.Reasons, "; "))
+		answer, err := line.Prompt("Type 'yes' to run it anyway: ")
+		if err != nil || strings.TrimSpace(answer) != "yes" {
+			fmt.Println(color.New(color.FgYellow).Sprint("Skipped."))
+			return
+		}
+	}
+
+	appendHistory(line, command)
+	history.Add(command)
+	executeInput(command, spinner)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/lint"
+	"github/0PrashantYadav0/GO-TERM/internal/policy"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// handleScriptCommand has the AI write a script for a task, lints it, saves
+// it, and offers to edit it or dry-run it through the risk guard
+func handleScriptCommand(args []string, line *liner.State, spinner *ui.Spinner) error {
+	usage := fmt.Errorf("usage: hscript [--goterm] [--show-redacted] [--no-cache|--refresh] <description> -o <file>")
+
+	// -o and --goterm may come anywhere, as in hscript deploy the site -o deploy.sh
+	output, kind := "", lint.Bash
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case (args[i] == "-o" || args[i] == "--output") && i+1 < len(args):
+			output = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--output="):
+			output = strings.TrimPrefix(args[i], "--output=")
+		case args[i] == "--goterm":
+			kind = lint.GoTerm
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	flags, rest, err := parseAIFlags(rest)
+	if err != nil || len(rest) == 0 || output == "" {
+		return usage
+	}
+	description := strings.Join(rest, " ")
+
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	keyColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+
+	var script *ai.Script
+	var findings []lint.Finding
+	for {
+		var previous string
+		var problems []string
+		if script != nil {
+			previous = script.Text
+			for _, f := range findings {
+				problems = append(problems, f.String())
+			}
+		}
+
+		ctx, info := aiContext(flags, spinner)
+		if previous == "" {
+			spinner.Start(color.New(color.FgCyan).Sprint("✨ Writing the script..."))
+		} else {
+			spinner.Start(color.New(color.FgCyan).Sprintf("✨ Fixing %d problems...", len(problems)))
+		}
+		generated, err := ai.GenerateScript(ctx, description, kind, previous, problems)
+		spinner.Stop()
+		printCallInfo(info)
+
+		if err != nil {
+			printAIError("Error writing script:", err)
+			return nil
+		}
+		script = generated
+		findings = lint.Check(script.Text, kind)
+
+		printScript(output, script.Text, findings)
+		if script.Explanation != "" {
+			fmt.Println("  " + color.New(color.FgHiWhite).Sprint(script.Explanation))
+		}
+		if len(findings) == 0 {
+			break
+		}
+
+		fmt.Print(keyColor("[s]") + "ave anyway  " + keyColor("[f]") + "ix  " + hintColor("any other key to discard "))
+		key, err := ui.ReadKey()
+		fmt.Println()
+		if err != nil || key == "s" || key == "S" {
+			// Not an interactive terminal: save it with the findings shown
+			break
+		}
+		if key != "f" && key != "F" {
+			return nil
+		}
+	}
+
+	if saved, err := saveScript(output, script.Text, kind, line); err != nil || !saved {
+		return err
+	}
+
+	text := script.Text
+	for {
+		fmt.Print(keyColor("[e]") + "dit  " + keyColor("[d]") + "ry-run  " + hintColor("any other key to finish "))
+		key, err := ui.ReadKey()
+		fmt.Println()
+		if err != nil {
+			return nil
+		}
+
+		switch key {
+		case "e", "E":
+			edited, err := ui.EditText(text, "hscript-*"+filepath.Ext(output))
+			if err != nil {
+				return err
+			}
+			if edited == text {
+				continue
+			}
+			text = edited
+			if err := os.WriteFile(output, []byte(text), 0o644); err != nil {
+				return err
+			}
+			findings := lint.Check(text, kind)
+			printFindings(findings)
+			fmt.Println(color.New(color.FgGreen, color.Bold).Sprintf("✓ Saved %s", output) + hintColor(fmt.Sprintf(" (%d findings)", len(findings))))
+
+		case "d", "D":
+			dryRunScript(text)
+
+		default:
+			return nil
+		}
+	}
+}
+
+// printScript shows a script with line numbers and each finding under the
+// line it is about
+func printScript(name, text string, findings []lint.Finding) {
+	numberColor := color.New(color.FgHiBlack).SprintFunc()
+	warnColor := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint("📜 " + name))
+	for _, f := range findings {
+		if f.Line == 0 {
+			fmt.Println(warnColor("     ⚠ " + f.Message))
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, l := range lines {
+		fmt.Println(numberColor(fmt.Sprintf("%4d │ ", i+1)) + l)
+		for _, f := range findings {
+			if f.Line == i+1 {
+				fmt.Println(numberColor("     │ ") + warnColor("⚠ "+f.Message))
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		fmt.Println(color.New(color.FgGreen).Sprint("  ✓ No lint findings"))
+	} else {
+		fmt.Println(warnColor(fmt.Sprintf("  %d lint findings", len(findings))))
+	}
+}
+
+// printFindings lists lint findings on their own
+func printFindings(findings []lint.Finding) {
+	for _, f := range findings {
+		fmt.Println("  " + color.New(color.FgYellow).Sprint("⚠ "+f.String()))
+	}
+}
+
+// saveScript writes a script, asking before it replaces a file. Bash
+// scripts are made executable.
+func saveScript(path, text, kind string, line *liner.State) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		answer, err := line.Prompt(fmt.Sprintf("%s exists. Type 'yes' to overwrite it: ", path))
+		if err != nil || strings.TrimSpace(answer) != "yes" {
+			fmt.Println(color.New(color.FgYellow).Sprint("Not saved."))
+			return false, nil
+		}
+	}
+
+	mode := os.FileMode(0o644)
+	if kind == lint.Bash {
+		mode = 0o755
+	}
+	if err := os.WriteFile(path, []byte(text), mode); err != nil {
+		return false, err
+	}
+	// WriteFile keeps the mode of a file it replaces
+	if err := os.Chmod(path, mode); err != nil {
+		return false, err
+	}
+
+	fmt.Println(color.New(color.FgGreen, color.Bold).Sprintf("✓ Saved %s", path))
+	return true, nil
+}
+
+// scriptSyntax matches lines that are only shell structure, with no command
+// for the guard to judge
+var scriptSyntax = regexp.MustCompile(`^(then|else|fi|do|done|esac|\{|\}|;;|set\s.*|[A-Za-z_]\w*\s*\(\)\s*\{?)$`)
+
+// dryRunScript runs each line of a script past the risk guard without
+// running anything, so the risky steps stand out
+func dryRunScript(text string) {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	riskColors := map[policy.Risk]*color.Color{
+		policy.RiskLow:    color.New(color.FgGreen, color.Bold),
+		policy.RiskMedium: color.New(color.FgYellow, color.Bold),
+		policy.RiskHigh:   color.New(color.FgRed, color.Bold),
+	}
+
+	fmt.Println(color.New(color.FgMagenta, color.Bold).Sprint("🧪 Dry run (nothing is executed):"))
+	counts := map[policy.Risk]int{}
+	for i, l := range strings.Split(text, "\n") {
+		command := strings.TrimSpace(l)
+		if command == "" || strings.HasPrefix(command, "#") || scriptSyntax.MatchString(command) {
+			continue
+		}
+
+		assessment := policy.Assess(command)
+		counts[assessment.Risk]++
+		note := ""
+		if assessment.Risk == policy.RiskLow && policy.IsReadOnly(command) {
+			note = " · reads only"
+		}
+		fmt.Printf("%s %s %s%s\n", hintColor(fmt.Sprintf("%4d", i+1)), riskColors[assessment.Risk].Sprintf("● %-6s", assessment.Risk), command, hintColor(note))
+		for _, reason := range assessment.Reasons {
+			fmt.Println("            " + hintColor("⚠ "+reason))
+		}
+	}
+
+	summary := fmt.Sprintf("%d steps: %d high, %d medium, %d low risk", counts[policy.RiskLow]+counts[policy.RiskMedium]+counts[policy.RiskHigh], counts[policy.RiskHigh], counts[policy.RiskMedium], counts[policy.RiskLow])
+	if counts[policy.RiskHigh] > 0 {
+		fmt.Println(color.New(color.FgRed, color.Bold).Sprint("⚠ " + summary))
+	} else {
+		fmt.Println(hintColor(summary))
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/clipboard"
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+	"github/0PrashantYadav0/GO-TERM/internal/translate"
+	"github/0PrashantYadav0/GO-TERM/internal/ui"
+	"github/0PrashantYadav0/GO-TERM/pkg/utils"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/peterh/liner"
+)
+
+// minDiffColumn is the narrowest a side of the translation diff gets
+const minDiffColumn = 20
+
+// handleTranslateCommand translates a command for another platform or
+// shell, with the local rules first and the AI for what they can't do.
+// input is the line as typed, so the command keeps its quoting.
+func handleTranslateCommand(input string, args []string, line *liner.State, history *terminal.History, spinner *ui.Spinner) error {
+	usage := fmt.Errorf("usage: htr --to %s [--no-ai] <command>", strings.Join(translate.Targets, "|"))
+
+	target, noAI := "", false
+	var aiArgs []string
+	consumed := 1 // the htr itself
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		arg := args[0]
+		args = args[1:]
+		consumed++
+		if arg == "--" {
+			break
+		}
+
+		switch {
+		case arg == "--to" && len(args) > 0:
+			target = args[0]
+			args = args[1:]
+			consumed++
+		case strings.HasPrefix(arg, "--to="):
+			target = strings.TrimPrefix(arg, "--to=")
+		case arg == "--no-ai":
+			noAI = true
+		default:
+			aiArgs = append(aiArgs, arg)
+		}
+	}
+
+	flags, rest, err := parseAIFlags(aiArgs)
+	if err != nil || len(rest) > 0 || target == "" || len(args) == 0 {
+		return usage
+	}
+	target = strings.ToLower(target)
+
+	command := unquoteCommand(wordsAfter(input, consumed))
+	result, err := translate.Translate(command, target)
+	if err != nil {
+		return err
+	}
+
+	translated, source := result.Command, "local rules"
+	var suggestion *ai.Suggestion
+	if !result.Complete && !noAI {
+		ctx, info := aiContext(flags, spinner)
+		spinner.Start(color.New(color.FgCyan).Sprintf("✨ Translating for %s...", target))
+		suggestion, err = ai.TranslateCommand(ctx, command, target, result)
+		spinner.Stop()
+		printCallInfo(info)
+
+		if err != nil {
+			printAIError("Error translating command:", err)
+			suggestion = nil
+		} else {
+			translated, source = suggestion.Command, "AI"
+		}
+	}
+
+	printTranslation(command, translated, target)
+	printTranslationNotes(result, suggestion, source)
+
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	if translated == command {
+		if noAI && len(result.Gaps) > 0 {
+			fmt.Println(hintColor("The local rules can't translate this; run without --no-ai to ask the AI"))
+		} else if noAI && !result.Complete {
+			fmt.Println(hintColor("No local rule applies; run without --no-ai to ask the AI"))
+		}
+		return nil
+	}
+	if len(result.Gaps) > 0 && suggestion == nil {
+		fmt.Println(color.New(color.FgYellow).Sprint("The local rules couldn't translate all of it; check the command before using it"))
+	}
+
+	// Only a command for this platform's shell can be run from here
+	current := translate.Current()
+	if target == current && (current == translate.Linux || current == translate.MacOS) {
+		risk := ""
+		if suggestion != nil {
+			risk = suggestion.RiskLevel
+		}
+		reviewCommand(translated, risk, line, history, spinner)
+		return nil
+	}
+	offerCopy(translated)
+	return nil
+}
+
+// unquoteCommand strips the quotes from a command given as one quoted word,
+// as in htr --to fish "export A=1"
+func unquoteCommand(command string) string {
+	if !strings.HasPrefix(command, "'") && !strings.HasPrefix(command, `"`) {
+		return command
+	}
+	if words, err := terminal.SplitArgs(command); err == nil && len(words) == 1 {
+		return words[0]
+	}
+	return command
+}
+
+// printTranslation shows the original and translated command side by side,
+// with removed words red on the left and added words green on the right
+func printTranslation(original, translated, target string) {
+	borderColor := color.New(color.FgHiBlack).SprintFunc()
+	headerColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+
+	column := max((utils.GetTerminalWidth()-7)/2, minDiffColumn)
+	left, right := diffWords(strings.Fields(original), strings.Fields(translated))
+	leftLines, rightLines := wrapText(left, column), wrapText(right, column)
+
+	fmt.Println(borderColor("┌─" + strings.Repeat("─", column) + "─┬─" + strings.Repeat("─", column) + "─┐"))
+	printDiffRow(headerColor("original"), headerColor(target), column)
+	fmt.Println(borderColor("├─" + strings.Repeat("─", column) + "─┼─" + strings.Repeat("─", column) + "─┤"))
+	for i := 0; i < max(len(leftLines), len(rightLines)); i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = leftLines[i]
+		}
+		if i < len(rightLines) {
+			r = rightLines[i]
+		}
+		printDiffRow(l, r, column)
+	}
+	fmt.Println(borderColor("└─" + strings.Repeat("─", column) + "─┴─" + strings.Repeat("─", column) + "─┘"))
+}
+
+// printDiffRow prints one row of the side-by-side diff, padding each side
+// to column visible characters
+func printDiffRow(left, right string, column int) {
+	border := color.New(color.FgHiBlack).Sprint("│")
+	pad := func(text string) string {
+		return text + strings.Repeat(" ", max(column-utf8.RuneCountInString(utils.StripAnsi(text)), 0))
+	}
+	fmt.Println(border + " " + pad(left) + " " + border + " " + pad(right) + " " + border)
+}
+
+// diffWords colours the words of before missing from after red, and the
+// words of after missing from before green, using their longest common
+// subsequence
+func diffWords(before, after []string) (string, string) {
+	removed := color.New(color.FgRed, color.Bold).SprintFunc()
+	added := color.New(color.FgGreen, color.Bold).SprintFunc()
+
+	// common[i][j] is the LCS length of before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var left, right []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			left = append(left, before[i])
+			right = append(right, after[j])
+			i++
+			j++
+		case j == len(after) || (i < len(before) && common[i+1][j] >= common[i][j+1]):
+			left = append(left, removed(before[i]))
+			i++
+		default:
+			right = append(right, added(after[j]))
+			j++
+		}
+	}
+	return strings.Join(left, " "), strings.Join(right, " ")
+}
+
+// printTranslationNotes lists what the rules changed, what they couldn't
+// handle, and where the translation came from
+func printTranslationNotes(result translate.Result, suggestion *ai.Suggestion, source string) {
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	warnColor := color.New(color.FgYellow).SprintFunc()
+
+	for _, change := range result.Changes {
+		if change.Before == change.After {
+			fmt.Println("  " + hintColor("• "+change.Note))
+		} else {
+			fmt.Println("  " + hintColor("• "+change.Before+" → "+change.After+": "+change.Note))
+		}
+	}
+	for _, gap := range result.Gaps {
+		fmt.Println("  " + warnColor("⚠ "+gap))
+	}
+
+	fmt.Println(hintColor("  translated by " + source))
+	if suggestion != nil && suggestion.Explanation != "" {
+		fmt.Println("  " + color.New(color.FgHiWhite).Sprint(suggestion.Explanation))
+	}
+	if suggestion != nil {
+		for _, alt := range suggestion.Alternatives {
+			fmt.Println("  " + hintColor("or: ") + color.New(color.FgCyan).Sprint(alt))
+		}
+	}
+}
+
+// offerCopy offers to copy a command meant for somewhere else
+func offerCopy(command string) {
+	keyColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Print(keyColor("[c]") + "opy  " + hintColor("any other key to skip "))
+	key, err := ui.ReadKey()
+	fmt.Println()
+	if err != nil || (key != "c" && key != "C") {
+		return
+	}
+
+	if err := clipboard.Write(command); err == nil {
+		fmt.Println(color.New(color.FgGreen, color.Bold).Sprint("✓ Command copied to clipboard"))
+	} else {
+		fmt.Println(color.New(color.FgRed, color.Bold).Sprint("Could not copy to clipboard:"), err)
+	}
+}
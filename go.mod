@@ -6,6 +6,7 @@ require (
 	github.com/atotto/clipboard v0.1.4
 	github.com/fatih/color v1.18.0
 	github.com/peterh/liner v1.2.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,332 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+// agentStepSchema describes the JSON object the model returns for each step
+var agentStepSchema = &Schema{
+	Type: "OBJECT",
+	Properties: map[string]*Schema{
+		"plan":    {Type: "ARRAY", Items: &Schema{Type: "STRING"}},
+		"thought": {Type: "STRING", Description: "One sentence on why this step comes next"},
+		"command": {Type: "STRING", Description: "The single-line shell command to run, empty when done"},
+		"done":    {Type: "BOOLEAN"},
+		"summary": {Type: "STRING", Description: "The outcome, when done"},
+	},
+	Required: []string{"thought", "command", "done"},
+}
+
+// AgentStep is the model's proposal for the next step
+type AgentStep struct {
+	Plan    []string `json:"plan"`
+	Thought string   `json:"thought"`
+	Command string   `json:"command"`
+	Done    bool     `json:"done"`
+	Summary string   `json:"summary"`
+}
+
+// Step outcomes recorded in an agent transcript
+const (
+	StepRan     = "ran"
+	StepSkipped = "skipped"
+)
+
+// AgentRecord is one proposed step and what happened to it
+type AgentRecord struct {
+	Thought      string        `json:"thought"`
+	Command      string        `json:"command"`
+	Outcome      string        `json:"outcome"`
+	AutoApproved bool          `json:"auto_approved,omitempty"`
+	Edited       bool          `json:"edited,omitempty"`
+	Stdout       string        `json:"stdout,omitempty"`
+	Stderr       string        `json:"stderr,omitempty"`
+	ExitCode     int           `json:"exit_code"`
+	Duration     time.Duration `json:"duration"`
+	Time         time.Time     `json:"time"`
+}
+
+// AgentSession is a goal and the steps taken toward it
+type AgentSession struct {
+	Name    string        `json:"name,omitempty"`
+	Goal    string        `json:"goal"`
+	Steps   []AgentRecord `json:"steps"`
+	Summary string        `json:"summary,omitempty"`
+	Done    bool          `json:"done"`
+	Created time.Time     `json:"created"`
+	Updated time.Time     `json:"updated"`
+}
+
+// AgentInfo summarizes a saved agent transcript
+type AgentInfo struct {
+	Name    string
+	Goal    string
+	Steps   int
+	Done    bool
+	Updated time.Time
+}
+
+// NewAgentSession starts a session for goal
+func NewAgentSession(goal string) *AgentSession {
+	now := time.Now()
+	return &AgentSession{Goal: goal, Created: now, Updated: now}
+}
+
+// Next asks the model for the next step given everything done so far
+func (s *AgentSession) Next(ctx context.Context) (*AgentStep, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := s.request()
+	if err != nil {
+		return nil, err
+	}
+
+	// Agent steps depend on live command output, so they are never cached
+	responseText, err := sendGeminiRequest(ctx, apiKey, aiCall{kind: "agent", uncached: true, request: request})
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(responseText)
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	var step AgentStep
+	if err := json.Unmarshal([]byte(text), &step); err != nil {
+		return nil, fmt.Errorf("invalid response from model: %w", err)
+	}
+
+	step.Command = cleanCommand(step.Command)
+	if isNoAnswer(step.Command) {
+		return nil, noAnswer("the model could not plan a next step")
+	}
+	if !step.Done && step.Command == "" {
+		return nil, errors.New("invalid response from model: no command and not done")
+	}
+
+	return &step, nil
+}
+
+// Record appends a step to the transcript
+func (s *AgentSession) Record(record AgentRecord) {
+	record.Time = time.Now()
+	s.Steps = append(s.Steps, record)
+	s.Updated = record.Time
+}
+
+// Finish marks the session complete with the model's summary
+func (s *AgentSession) Finish(summary string) {
+	s.Done = true
+	s.Summary = summary
+	s.Updated = time.Now()
+}
+
+// request replays the session as alternating model proposals and results
+func (s *AgentSession) request() (GeminiRequest, error) {
+	instruction, err := prompts.Render("agent", promptData(s.Goal, s.Goal, nil))
+	if err != nil {
+		return GeminiRequest{}, err
+	}
+
+	request := GeminiRequest{
+		SystemInstruction: &Content{Parts: []Part{{Text: instruction}}},
+		Contents: []Content{
+			{Role: "user", Parts: []Part{{Text: "Goal: " + s.Goal}}},
+		},
+		GenerationConfig: &GenerationConfig{
+			ResponseMimeType: "application/json",
+			ResponseSchema:   agentStepSchema,
+		},
+	}
+
+	for _, step := range s.Steps {
+		proposal, _ := json.Marshal(AgentStep{Thought: step.Thought, Command: step.Command})
+		request.Contents = append(request.Contents,
+			Content{Role: "model", Parts: []Part{{Text: string(proposal)}}},
+			Content{Role: "user", Parts: []Part{{Text: step.feedback()}}},
+		)
+	}
+
+	return request, nil
+}
+
+// feedback describes a step's result to the model
+func (r AgentRecord) feedback() string {
+	if r.Outcome == StepSkipped {
+		return "The user declined to run that command. Propose a different approach, or finish if the goal cannot be reached."
+	}
+
+	var b strings.Builder
+	if r.Edited {
+		fmt.Fprintf(&b, "The user edited the command and ran: %s\n", r.Command)
+	}
+	fmt.Fprintf(&b, "exit code: %d\n", r.ExitCode)
+	fmt.Fprintf(&b, "stdout:\n%s\n", orNone(r.Stdout))
+	fmt.Fprintf(&b, "stderr:\n%s", orNone(r.Stderr))
+	return b.String()
+}
+
+// orNone marks empty output explicitly for the model
+func orNone(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "(none)"
+	}
+	return text
+}
+
+// Markdown renders the transcript for sharing
+func (s *AgentSession) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Agent: %s\n\n", s.Goal)
+	fmt.Fprintf(&b, "Started %s, %d steps\n", s.Created.Format("2006-01-02 15:04"), len(s.Steps))
+
+	for i, step := range s.Steps {
+		fmt.Fprintf(&b, "\n## Step %d\n\n", i+1)
+		if step.Thought != "" {
+			fmt.Fprintf(&b, "%s\n\n", step.Thought)
+		}
+		fmt.Fprintf(&b, "```sh\n%s\n```\n\n", step.Command)
+
+		switch {
+		case step.Outcome == StepSkipped:
+			b.WriteString("Skipped by the user.\n")
+			continue
+		case step.AutoApproved:
+			b.WriteString("Auto-approved (read-only).\n\n")
+		}
+
+		fmt.Fprintf(&b, "Exit code %d in %s\n", step.ExitCode, step.Duration.Round(time.Millisecond))
+		if strings.TrimSpace(step.Stdout) != "" {
+			fmt.Fprintf(&b, "\n```\n%s\n```\n", strings.TrimRight(step.Stdout, "\n"))
+		}
+		if strings.TrimSpace(step.Stderr) != "" {
+			fmt.Fprintf(&b, "\nstderr:\n\n```\n%s\n```\n", strings.TrimRight(step.Stderr, "\n"))
+		}
+	}
+
+	if s.Summary != "" {
+		fmt.Fprintf(&b, "\n## Summary\n\n%s\n", s.Summary)
+	}
+	return b.String()
+}
+
+// getAgentDir returns the directory where agent transcripts are stored
+func getAgentDir() (string, error) {
+	dir := filepath.Join(config.GetConfigDir(), "agent")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// agentPath returns the file path for a transcript name
+func agentPath(name string) (string, error) {
+	name = conversationNamePattern.ReplaceAllString(strings.TrimSpace(name), "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		return "", errors.New("transcript name cannot be empty")
+	}
+
+	dir, err := getAgentDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveAgentSession writes the transcript under its name, naming it after
+// its start time if it has none
+func SaveAgentSession(s *AgentSession) error {
+	if s.Name == "" {
+		s.Name = s.Created.Format("20060102-150405")
+	}
+
+	path, err := agentPath(s.Name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadAgentSession reads a saved transcript by name
+func LoadAgentSession(name string) (*AgentSession, error) {
+	path, err := agentPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("agent transcript %q not found", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var s AgentSession
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// ListAgentSessions returns saved transcripts, most recently updated first
+func ListAgentSessions() ([]AgentInfo, error) {
+	dir, err := getAgentDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []AgentInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		s, err := LoadAgentSession(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+
+		infos = append(infos, AgentInfo{
+			Name:    s.Name,
+			Goal:    s.Goal,
+			Steps:   len(s.Steps),
+			Done:    s.Done,
+			Updated: s.Updated,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Updated.After(infos[j].Updated)
+	})
+
+	return infos, nil
+}
@@ -7,17 +7,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/internal/auth"
+	"github/0PrashantYadav0/GO-TERM/internal/environment"
+	"github/0PrashantYadav0/GO-TERM/internal/manpage"
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"github/0PrashantYadav0/GO-TERM/pkg/logger"
 )
 
-type Config struct {
-	GeminiAPIKey string `json:"gemini_apiKey"`
-}
-
 type CommandLog struct {
 	ID        string `json:"id"`
 	Timestamp string `json:"timestamp"`
@@ -28,6 +33,7 @@ type CommandLog struct {
 		CWD        string   `json:"cwd"`
 	} `json:"command"`
 	Output struct {
+		Stdout   string `json:"stdout,omitempty"`
 		Stderr   string `json:"stderr"`
 		ExitCode int    `json:"exitCode"`
 		Error    string `json:"error,omitempty"`
@@ -39,12 +45,21 @@ type CommandLog struct {
 	} `json:"metadata"`
 }
 
+// Part is a single piece of text within a message
+type Part struct {
+	Text string `json:"text"`
+}
+
+// Content is one turn of a conversation; Role is "user" or "model"
+type Content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []Part `json:"parts"`
+}
+
 type GeminiRequest struct {
-	Contents []struct {
-		Parts []struct {
-			Text string `json:"text"`
-		} `json:"parts"`
-	} `json:"contents"`
+	SystemInstruction *Content          `json:"systemInstruction,omitempty"`
+	Contents          []Content         `json:"contents"`
+	GenerationConfig  *GenerationConfig `json:"generationConfig,omitempty"`
 }
 
 type GeminiResponse struct {
@@ -54,88 +69,42 @@ type GeminiResponse struct {
 				Text string `json:"text"`
 			} `json:"parts"`
 		} `json:"content"`
+		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata tokenUsage `json:"usageMetadata"`
+}
+
+// geminiReply is the answer to one generateContent call and what it cost
+type geminiReply struct {
+	text  string
+	usage tokenUsage
 }
 
 const (
-	instructionForHm = `
-- As an intelligent assistant, interpret the user's intent accurately. Provide precise shell commands in response, based on your analysis of the user's input and any errors they encountered.
-- Your goal is to assist the user by giving them only the correct command they need to execute, formatted without explanations or additional details. Assume the user has a minimal shell environment installed and respond with the exact command they should run.
-- Be concise and efficient, responding with only the command.
-- platform %s
-- Be very smart
-- Do not hallucinate
-- **Note:** If you're unsure of the correct response, or prefer not to answer for any reason, reply only with the UUID: 3d8a19a704.
-`
-
-	instructionForHp = `
-- You are a command-line assistant, helping users run commands in a shell environment. Analyze the user's input and determine the exact shell command they need to execute, assuming they have a basic installation.
-- Respond solely with the unformatted command line instruction, omitting any explanations or extraneous text.
-- Focus on providing precise commands, interpreting user input efficiently and accurately to meet their needs.
-- platform %s
-- Be very smart
-- Do not hallucinate
-- **Note:** If you're unsure of the correct response, or prefer not to answer for any reason, reply only with the UUID: 3d8a19a704.
-`
-
-	instructionForExplain = `
-You are a smart command-line assistant. The question the user has asked is -> %s
-Explain it to the user properly, focusing on command-line concepts. If you cannot explain something just respond with 3d8a19a704 and nothing else. The output will be passed to a terminal so keep it clean and use clear formatting.
-`
-
-	instructionForChat = `
-You are a helpful assistant answering a user's question. Provide a concise, informative answer in 3-4 lines maximum.
-Be accurate, to the point, and helpful.
-
-The question is: %s
-
-Remember to keep your answer to 3-4 lines maximum.
-`
+	geminiBaseURL  = "https://generativelanguage.googleapis.com/v1beta/models"
+	geminiProvider = "gemini"
 )
 
+// getApiKey resolves the API key from the configured credential sources
 func getApiKey() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if replaying {
+		// Fixtures answer without the provider, so no key is needed
+		return "", nil
 	}
-
-	configPath := filepath.Join(homeDir, ".goterm.json")
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return "", errors.New("API key not found")
-	}
-
-	fileData, err := os.ReadFile(configPath)
+	key, source, err := auth.Resolve()
 	if err != nil {
 		return "", err
 	}
-
-	var config Config
-	if err := json.Unmarshal(fileData, &config); err != nil {
-		return "", err
-	}
-
-	if config.GeminiAPIKey == "" {
-		return "", errors.New("API key is empty")
-	}
-
-	return config.GeminiAPIKey, nil
+	logger.Debug("Using API key from %s", source)
+	return key, nil
 }
 
-func saveApiKey(apiKey string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	configPath := filepath.Join(homeDir, ".goterm.json")
-	config := Config{GeminiAPIKey: apiKey}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(configPath, data, 0600)
+// saveApiKey stores the key in the keyring if available, else the config file
+func saveApiKey(apiKey string) (string, error) {
+	return auth.Login(apiKey, false)
 }
 
 func getLastCommandLog() (*CommandLog, error) {
@@ -166,123 +135,394 @@ func getLastCommandLog() (*CommandLog, error) {
 	return &logs[len(logs)-1], nil
 }
 
-func GenerateCommandForHm(ctx context.Context) (string, error) {
+func GenerateCommandForHm(ctx context.Context) (*Suggestion, error) {
+	lastLog, err := getLastCommandLog()
+	if err != nil {
+		return nil, err
+	}
+
+	return GenerateFix(ctx, lastLog)
+}
+
+// GenerateFix suggests a fix for a failed command from its log entry
+func GenerateFix(ctx context.Context, failed *CommandLog) (*Suggestion, error) {
 	apiKey, err := getApiKey()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	lastLog, err := getLastCommandLog()
+	fullPrompt, err := prompts.Render("hm", promptData(failed.Command.Raw, failed.Command.Raw, failed))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	cacheQuery := fmt.Sprintf("%s\n%s\nexit %d", failed.Command.Raw, failed.Output.Stderr, failed.Output.ExitCode)
 
-	prompt := fmt.Sprintf(instructionForHm, runtime.GOOS)
-	lastLogJSON, err := json.Marshal(lastLog)
+	return generateSuggestion(ctx, apiKey, "hm", templateQuery("hm", cacheQuery), fullPrompt)
+}
+
+func GenerateCommandForHp(ctx context.Context, query string) (*Suggestion, error) {
+	apiKey, err := getApiKey()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	fullPrompt := prompt + "\n" + string(lastLogJSON)
+	fullPrompt, err := prompts.Render("hp", promptData(query, query, nil))
+	if err != nil {
+		return nil, err
+	}
 
-	return callGeminiAPI(ctx, apiKey, fullPrompt)
+	return generateSuggestion(ctx, apiKey, "hp", templateQuery("hp", query), fullPrompt)
 }
 
-func GenerateCommandForHp(ctx context.Context, query string) (string, error) {
+// GenerateCandidatesForHp asks the model for several alternative commands
+func GenerateCandidatesForHp(ctx context.Context, query string, count int) ([]*Suggestion, error) {
+	if count <= 1 {
+		suggestion, err := GenerateCommandForHp(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return []*Suggestion{suggestion}, nil
+	}
+
 	apiKey, err := getApiKey()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	data := promptData(query, query, nil)
+	data.Count = count
+	prompt, err := prompts.Render("hp", data)
+	if err != nil {
+		return nil, err
+	}
+	instruction, err := prompts.Render("hp_candidates", data)
+	if err != nil {
+		return nil, err
+	}
+	fullPrompt := prompt + instruction
+
+	call := aiCall{
+		kind:  fmt.Sprintf("hp-%d", count),
+		query: templateQuery("hp_candidates", templateQuery("hp", query)),
+		request: GeminiRequest{
+			Contents: []Content{
+				{Role: "user", Parts: []Part{{Text: fullPrompt}}},
+			},
+			GenerationConfig: &GenerationConfig{
+				ResponseMimeType: "application/json",
+				ResponseSchema:   candidatesSchema,
+			},
+		},
+	}
+
+	responseText, err := sendGeminiRequest(ctx, apiKey, call)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := parseCandidates(responseText)
+	if err != nil {
+		return nil, err
 	}
 
-	prompt := fmt.Sprintf(instructionForHp, runtime.GOOS)
-	fullPrompt := prompt + "\n" + query
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
 
-	return callGeminiAPI(ctx, apiKey, fullPrompt)
+	return candidates, nil
 }
 
-func ExplainCommand(ctx context.Context, query string) (string, error) {
+// ExplainCommand explains a command or concept. Pages from the local
+// documentation are included so flags are described as installed, and the
+// model cites them.
+func ExplainCommand(ctx context.Context, query string, pages []manpage.Page) (string, error) {
 	apiKey, err := getApiKey()
 	if err != nil {
 		return "", err
 	}
 
-	prompt := fmt.Sprintf(instructionForExplain, query)
+	data := promptData(query, query, nil)
+	prompt, err := prompts.Render("he", data)
+	if err != nil {
+		return "", err
+	}
+
+	// The installed documentation is part of what the answer depends on
+	cacheQuery := templateQuery("he", query)
+	if len(pages) > 0 {
+		grounding, err := prompts.Render("he_grounding", data)
+		if err != nil {
+			return "", err
+		}
+		prompt += grounding + manpage.FormatAll(pages) + "\n"
+		cacheQuery = templateQuery("he_grounding", cacheQuery)
+	}
+	for _, page := range pages {
+		cacheQuery += "\n" + page.Cite()
+	}
 
-	return callGeminiAPI(ctx, apiKey, prompt)
+	return generateText(ctx, apiKey, "he", cacheQuery, prompt)
 }
 
-func ChatWithAI(ctx context.Context, question string) (string, error) {
+// ChatWithAI answers a single question. Attached files and command output
+// are placed before the question.
+func ChatWithAI(ctx context.Context, question string, attachments []Attachment) (string, error) {
 	apiKey, err := getApiKey()
 	if err != nil {
 		return "", err
 	}
 
-	prompt := fmt.Sprintf(instructionForChat, question)
+	prompt, err := prompts.Render("chat", promptData(question, question, nil))
+	if err != nil {
+		return "", err
+	}
+	prompt = formatAttachments(attachments) + prompt
 
-	return callGeminiAPI(ctx, apiKey, prompt)
+	return generateText(ctx, apiKey, "chat", templateQuery("chat", question)+attachmentsKey(attachments), prompt)
 }
 
-func callGeminiAPI(ctx context.Context, apiKey string, prompt string) (string, error) {
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1/models/gemini-1.5-flash:generateContent?key=%s", apiKey)
-
-	request := GeminiRequest{
-		Contents: []struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		}{
-			{
-				Parts: []struct {
-					Text string `json:"text"`
-				}{
-					{
-						Text: prompt,
-					},
-				},
+// generateText sends a single-turn prompt and returns the full response text
+func generateText(ctx context.Context, apiKey string, kind string, query string, prompt string) (string, error) {
+	call := aiCall{
+		kind:  kind,
+		query: query,
+		request: GeminiRequest{
+			Contents: []Content{
+				{Role: "user", Parts: []Part{{Text: prompt}}},
 			},
 		},
 	}
 
-	requestData, err := json.Marshal(request)
+	responseText, err := sendGeminiRequest(ctx, apiKey, call)
 	if err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestData))
+	if isNoAnswer(responseText) {
+		return "", noAnswer("the model declined to answer")
+	}
+
+	return responseText, nil
+}
+
+// generateSuggestion asks the model for a JSON command suggestion matching
+// suggestionSchema and parses it
+func generateSuggestion(ctx context.Context, apiKey string, kind string, query string, prompt string) (*Suggestion, error) {
+	call := aiCall{
+		kind:  kind,
+		query: query,
+		request: GeminiRequest{
+			Contents: []Content{
+				{Role: "user", Parts: []Part{{Text: prompt}}},
+			},
+			GenerationConfig: &GenerationConfig{
+				ResponseMimeType: "application/json",
+				ResponseSchema:   suggestionSchema,
+			},
+		},
+	}
+
+	responseText, err := sendGeminiRequest(ctx, apiKey, call)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	return parseSuggestion(responseText)
+}
+
+// aiCall is one request to the model along with what identifies it in the
+// response cache
+type aiCall struct {
+	kind     string // command type such as "hp"
+	uncached bool   // the answer depends on state the cache key can't capture
+	query    string // the user input, kept redacted with cached answers for offline matching
+	request  GeminiRequest
+}
+
+// sendGeminiRequest answers a call from the response cache or the model and
+// returns the full text of the answer
+func sendGeminiRequest(ctx context.Context, apiKey string, call aiCall) (string, error) {
+	opts := optionsFrom(ctx)
+	model := config.GetConfig().Model
+	useCache := !call.uncached && !opts.NoCache && config.GetConfig().Cache.Enabled
+
+	// The cache only ever sees redacted text, both in its keys and on disk
+	findings, err := redactRequest(&call.request)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	if call.query, err = redactText(call.query); err != nil {
+		return "", err
+	}
+	key := cacheKey(geminiProvider, model, call.kind, call.query, environment.Default.Setting())
+
+	if useCache && !opts.Refresh {
+		if response, ok := lookupCache(key); ok {
+			if opts.Info != nil {
+				opts.Info.Cached = true
+			}
+			return response, nil
+		}
+	}
+
+	if warning := checkDailyLimits(); warning != "" {
+		if opts.OverLimit != nil && !opts.OverLimit(warning) {
+			return "", ErrCancelled
+		}
+		logger.Info("%s", warning)
+	}
+
+	if opts.Preview != nil {
+		payload, err := json.MarshalIndent(call.request, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if !opts.Preview(string(payload), findings) {
+			return "", ErrCancelled
+		}
+	}
 
-	body, err := io.ReadAll(resp.Body)
+	start := time.Now()
+	reply, err := postGemini(ctx, apiKey, model, call.request)
+	if reply.usage.TotalTokens > 0 {
+		// Declined and blocked answers still consume tokens
+		if err := recordUsage(call.kind, model, reply.usage, time.Since(start)); err != nil {
+			logger.Error("Failed to record usage: %v", err)
+		}
+	}
+	response := reply.text
 	if err != nil {
+		if !call.uncached && !opts.NoCache && isNetworkError(err) {
+			return offlineFallback(key, call, opts.Info, err)
+		}
 		return "", err
 	}
 
+	if useCache && !isNoAnswer(response) {
+		_ = storeCache(cacheEntry{
+			Key:      key,
+			Provider: geminiProvider,
+			Model:    model,
+			Kind:     call.kind,
+			Query:    call.query,
+			Response: response,
+		})
+	}
+
+	return response, nil
+}
+
+// offlineFallback answers from the cache, ignoring age, when the network is down
+func offlineFallback(key string, call aiCall, info *CallInfo, networkErr error) (string, error) {
+	if entry, err := readCacheEntry(key); err == nil {
+		if info != nil {
+			info.Cached, info.Offline = true, true
+		}
+		return entry.Response, nil
+	}
+
+	if entry, ok := fuzzyLookup(geminiProvider, call.kind, call.query); ok {
+		if info != nil {
+			info.Cached, info.Offline = true, true
+			info.MatchedQuery = entry.Query
+		}
+		return entry.Response, nil
+	}
+
+	return "", networkErr
+}
+
+// isNetworkError reports whether err means the API could not be reached
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var urlErr *url.Error
+	return errors.As(err, &dnsErr) || errors.As(err, &opErr) ||
+		(errors.As(err, &urlErr) && urlErr.Timeout())
+}
+
+// postGemini posts a request to the generateContent endpoint, retrying
+// transient failures, and returns the full text of the first candidate
+func postGemini(ctx context.Context, apiKey string, model string, request GeminiRequest) (geminiReply, error) {
+	endpoint := fmt.Sprintf("%s/%s:generateContent", geminiBaseURL, model)
+
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return geminiReply{}, err
+	}
+
+	client := &http.Client{
+		Timeout:   time.Duration(config.GetConfig().DefaultTimeout) * time.Second,
+		Transport: httpTransport,
+	}
+
+	return withRetry(ctx, func() (geminiReply, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(requestData))
+		if err != nil {
+			return geminiReply{}, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		// Sent as a header so the key stays out of proxy and access logs
+		req.Header.Set("x-goog-api-key", apiKey)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return geminiReply{}, transportError(err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return geminiReply{}, transportError(err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := parseAPIError(resp, body)
+			logger.Error("Gemini API error: %d %s", resp.StatusCode, apiErr.Message)
+			return geminiReply{}, apiErr
+		}
+
+		return parseGeminiResponse(body)
+	})
+}
+
+// parseGeminiResponse extracts the answer text and token counts from a
+// successful response
+func parseGeminiResponse(body []byte) (geminiReply, error) {
 	var response GeminiResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", err
+		return geminiReply{}, err
 	}
+	reply := geminiReply{usage: response.UsageMetadata}
 
-	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
-		return "3d8a19a704", nil
+	if reason := response.PromptFeedback.BlockReason; reason != "" {
+		return reply, blockedError(reason)
 	}
 
-	responseText := response.Candidates[0].Content.Parts[0].Text
-	command := strings.TrimSpace(strings.Split(responseText, "\n")[0])
+	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
+		if len(response.Candidates) > 0 {
+			switch reason := response.Candidates[0].FinishReason; reason {
+			case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+				return reply, blockedError(reason)
+			}
+		}
+		return reply, noAnswer("the model returned an empty response")
+	}
 
-	if command == "" || command == "3d8a19a704" {
-		return "3d8a19a704", nil
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
 	}
 
-	return command, nil
+	reply.text = strings.TrimSpace(text.String())
+	if reply.text == "" {
+		return reply, noAnswer("the model returned an empty response")
+	}
+	return reply, nil
 }
 
 func CheckAndSetupApiKey() (bool, error) {
@@ -301,11 +541,11 @@ func CheckAndSetupApiKey() (bool, error) {
 		return false, errors.New("API key cannot be empty")
 	}
 
-	err = saveApiKey(apiKey)
+	source, err := saveApiKey(apiKey)
 	if err != nil {
 		return false, err
 	}
 
-	fmt.Println("⟨ ◠︶◠ ⟩ API key saved successfully!")
+	fmt.Printf("⟨ ◠︶◠ ⟩ API key saved successfully to the %s!\n", source)
 	return true, nil
 }
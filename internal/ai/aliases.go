@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+)
+
+// aliasesSchema describes the JSON object the model returns for alias suggest
+var aliasesSchema = &Schema{
+	Type: "OBJECT",
+	Properties: map[string]*Schema{
+		"aliases": {Type: "ARRAY", Items: &Schema{
+			Type: "OBJECT",
+			Properties: map[string]*Schema{
+				"index":       {Type: "INTEGER"},
+				"name":        {Type: "STRING"},
+				"command":     {Type: "STRING", Description: "The command the alias expands to, with $1 or $@ for parameters"},
+				"description": {Type: "STRING"},
+			},
+			Required: []string{"index", "name", "command"},
+		}},
+	},
+	Required: []string{"aliases"},
+}
+
+// aliasNamePattern is what the model may name an alias
+var aliasNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,11}$`)
+
+// AliasIdea is an alias mined from history, for the model to name and
+// generalize
+type AliasIdea struct {
+	Name        string   `json:"name"`
+	Command     string   `json:"command"`
+	Description string   `json:"description,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+}
+
+// ImproveAliases asks the model for better names, descriptions and, where
+// the examples allow, more general commands. Entries the model gets wrong
+// come back unchanged.
+func ImproveAliases(ctx context.Context, ideas []AliasIdea) ([]AliasIdea, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := json.MarshalIndent(ideas, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	instruction, err := prompts.Render("alias_suggest", promptData("", "", nil))
+	if err != nil {
+		return nil, err
+	}
+	prompt := instruction + "\n" + string(listing)
+
+	call := aiCall{
+		kind:  "alias",
+		query: templateQuery("alias_suggest", string(listing)),
+		request: GeminiRequest{
+			Contents: []Content{
+				{Role: "user", Parts: []Part{{Text: prompt}}},
+			},
+			GenerationConfig: &GenerationConfig{
+				ResponseMimeType: "application/json",
+				ResponseSchema:   aliasesSchema,
+			},
+		},
+	}
+
+	responseText, err := sendGeminiRequest(ctx, apiKey, call)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(responseText)
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	var response struct {
+		Aliases []struct {
+			Index       int    `json:"index"`
+			Name        string `json:"name"`
+			Command     string `json:"command"`
+			Description string `json:"description"`
+		} `json:"aliases"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		return nil, fmt.Errorf("invalid response from model: %w", err)
+	}
+
+	improved := make([]AliasIdea, len(ideas))
+	copy(improved, ideas)
+	for _, a := range response.Aliases {
+		if a.Index < 0 || a.Index >= len(ideas) {
+			continue
+		}
+		idea := &improved[a.Index]
+		if name := strings.ToLower(strings.TrimSpace(a.Name)); aliasNamePattern.MatchString(name) {
+			idea.Name = name
+		}
+		if command := cleanCommand(a.Command); command != "" && !strings.Contains(command, "\n") && !isNoAnswer(command) {
+			idea.Command = command
+		}
+		idea.Description = strings.TrimSpace(a.Description)
+	}
+
+	return improved, nil
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Attachment is a file or command output sent along with a chat message.
+// Only its name, size and note are saved with a conversation; the content
+// lives for the session. Like the rest of a request, it is redacted before
+// it is sent.
+type Attachment struct {
+	Name    string `json:"name"`
+	Size    int    `json:"size"`
+	Note    string `json:"note,omitempty"` // what part was sent, e.g. "lines 10-80 of 312"
+	Content string `json:"-"`
+}
+
+// Label describes an attachment for the transcript, e.g. "go.mod (1.2 KB)"
+func (a Attachment) Label() string {
+	label := fmt.Sprintf("%s (%s)", a.Name, formatSize(a.Size))
+	if a.Note != "" {
+		label += ", " + a.Note
+	}
+	return label
+}
+
+// formatAttachments renders attachments for a prompt. Those loaded from a
+// saved conversation have no content left and are only named.
+func formatAttachments(attachments []Attachment) string {
+	var b strings.Builder
+	for _, a := range attachments {
+		if a.Content == "" {
+			fmt.Fprintf(&b, "[%s was attached here; its contents were not kept]\n\n", a.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "<attachment name=%q", a.Name)
+		if a.Note != "" {
+			fmt.Fprintf(&b, " note=%q", a.Note)
+		}
+		b.WriteString(">\n" + strings.TrimRight(a.Content, "\n") + "\n</attachment>\n\n")
+	}
+	return b.String()
+}
+
+// attachmentsKey identifies attachment contents in a cache query
+func attachmentsKey(attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	for _, a := range attachments {
+		// Keyed like the query, on the text as it is sent
+		content, err := redactText(a.Content)
+		if err != nil {
+			content = a.Content
+		}
+		hash.Write([]byte(a.Name + "\x00" + content + "\x00"))
+	}
+	return "\nattachments " + hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+// formatSize renders a byte count for people
+func formatSize(size int) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KB", float64(size)/1024)
+}
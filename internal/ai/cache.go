@@ -0,0 +1,285 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+// fuzzyThreshold is the minimum word overlap for reusing an answer offline
+const fuzzyThreshold = 0.5
+
+// cacheEntry is one stored model response
+type cacheEntry struct {
+	Key      string    `json:"key"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Kind     string    `json:"kind"`
+	Query    string    `json:"query"`
+	Response string    `json:"response"`
+	Created  time.Time `json:"created"`
+}
+
+// CacheStats summarizes the response cache
+type CacheStats struct {
+	Entries int
+	Bytes   int64
+	Expired int
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// normalizeQuery lowercases a query and collapses its whitespace. Unlike
+// punctuation, neither changes which command is meant.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// cacheKey returns the content address of a request from its redacted
+// query, which carries the template fingerprint and attachments, and the
+// setting it was asked in. The live context block is left out, as running
+// any command changes it.
+func cacheKey(provider, model, kind, query, setting string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + model + "\x00" + kind + "\x00" + normalizeQuery(query) + "\x00" + setting))
+	return hex.EncodeToString(sum[:])
+}
+
+// getCacheDir returns the directory holding cached responses
+func getCacheDir() (string, error) {
+	dir := filepath.Join(config.GetConfigDir(), "cache")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// readCacheEntry loads an entry by key, ignoring its age
+func readCacheEntry(key string) (*cacheEntry, error) {
+	dir, err := getCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// expired reports whether an entry is older than the configured TTL
+func (e *cacheEntry) expired() bool {
+	ttl := time.Duration(config.GetConfig().Cache.TTLHours) * time.Hour
+	return ttl > 0 && time.Since(e.Created) > ttl
+}
+
+// lookupCache returns a fresh cached response for key
+func lookupCache(key string) (string, bool) {
+	entry, err := readCacheEntry(key)
+	if err != nil || entry.expired() {
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// storeCache saves a response and trims the cache to its size limit
+func storeCache(entry cacheEntry) error {
+	dir, err := getCacheDir()
+	if err != nil {
+		return err
+	}
+
+	entry.Created = time.Now()
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, entry.Key+".json"), data, 0600); err != nil {
+		return err
+	}
+
+	return pruneCache(dir)
+}
+
+// pruneCache removes expired entries, then the oldest ones until the cache
+// fits within the configured size
+func pruneCache(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	ttl := time.Duration(config.GetConfig().Cache.TTLHours) * time.Hour
+	var files []file
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if ttl > 0 && time.Since(info.ModTime()) > ttl*4 {
+			// Keep expired answers around for a while as an offline fallback
+			_ = os.Remove(path)
+			continue
+		}
+		files = append(files, file{path, info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	limit := int64(config.GetConfig().Cache.MaxSizeKB) * 1024
+	if limit <= 0 || total <= limit {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= limit {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+
+	return nil
+}
+
+// fuzzyLookup finds the stored answer whose query best matches query,
+// regardless of age. It is used only when the network is unreachable.
+func fuzzyLookup(provider, kind, query string) (*cacheEntry, bool) {
+	dir, err := getCacheDir()
+	if err != nil {
+		return nil, false
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false
+	}
+
+	words := wordSet(query)
+	var best *cacheEntry
+	bestScore := 0.0
+
+	for _, e := range entries {
+		entry, err := readCacheEntry(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil || entry.Provider != provider || entry.Kind != kind {
+			continue
+		}
+
+		if score := jaccard(words, wordSet(entry.Query)); score > bestScore {
+			best, bestScore = entry, score
+		}
+	}
+
+	if best == nil || bestScore < fuzzyThreshold {
+		return nil, false
+	}
+	return best, true
+}
+
+// wordSet returns the distinct words in s, ignoring case
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		set[word] = true
+	}
+	return set
+}
+
+// jaccard returns the overlap between two word sets
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// GetCacheStats reports the size of the response cache
+func GetCacheStats() (CacheStats, error) {
+	var stats CacheStats
+
+	dir, err := getCacheDir()
+	if err != nil {
+		return stats, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, e := range entries {
+		entry, err := readCacheEntry(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			stats.Bytes += info.Size()
+		}
+
+		stats.Entries++
+		if entry.expired() {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.Created.Before(stats.Oldest) {
+			stats.Oldest = entry.Created
+		}
+		if entry.Created.After(stats.Newest) {
+			stats.Newest = entry.Created
+		}
+	}
+
+	return stats, nil
+}
+
+// ClearCache deletes every cached response and returns how many were removed
+func ClearCache() (int, error) {
+	dir, err := getCacheDir()
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if err := os.Remove(filepath.Join(dir, e.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
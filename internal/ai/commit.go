@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+)
+
+// commitStyles describes each supported message style to the model
+var commitStyles = map[string]string{
+	"conventional": `Follow Conventional Commits: "type(scope): subject" where type is one of feat, fix, docs, style, refactor, perf, test, build, ci or chore, and the optional scope is the main area touched. Mark breaking changes with "!" after the type and a "BREAKING CHANGE:" footer.`,
+	"gitmoji":      `Follow gitmoji: start the subject with one emoji for the kind of change, such as ✨ new feature, 🐛 bug fix, 📝 docs, ♻️ refactor, ⚡️ performance, ✅ tests, 🔧 configuration, 🔥 removal or ⬆️ dependency upgrade, followed by a capitalized subject.`,
+	"plain":        `Write a plain capitalized subject with no type prefix or emoji.`,
+}
+
+// diffFileHeader starts each file's section in a git diff
+var diffFileHeader = regexp.MustCompile(`(?m)^diff --git `)
+
+// CommitStyles returns the supported commit message styles
+func CommitStyles() []string {
+	styles := make([]string, 0, len(commitStyles))
+	for style := range commitStyles {
+		styles = append(styles, style)
+	}
+	sort.Strings(styles)
+	return styles
+}
+
+// GenerateCommitMessage writes a commit message in the given style for a
+// staged diff. Diffs larger than budget bytes are summarized file by file
+// first, and the message is written from the summaries and the diffstat.
+func GenerateCommitMessage(ctx context.Context, diff string, stat string, style string, budget int) (string, error) {
+	rules, ok := commitStyles[style]
+	if !ok {
+		return "", fmt.Errorf("unknown commit style %q (use %s)", style, strings.Join(CommitStyles(), ", "))
+	}
+
+	apiKey, err := getApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	changes := "Staged diff:\n" + diff
+	if budget > 0 && len(diff) > budget {
+		summaries, err := summarizeFiles(ctx, apiKey, diff, budget)
+		if err != nil {
+			return "", err
+		}
+		changes = "The diff is too large to show, so here is a summary per file.\n\nDiffstat:\n" + stat + "\nSummaries:\n" + summaries
+	}
+
+	data := promptData("", "", nil)
+	data.Style = rules
+	instruction, err := prompts.Render("gcm", data)
+	if err != nil {
+		return "", err
+	}
+	message, err := generateText(ctx, apiKey, "gcm", templateQuery("gcm", style+"\n"+diff), instruction+"\n"+changes)
+	if err != nil {
+		return "", err
+	}
+
+	return cleanCommitMessage(message), nil
+}
+
+// summarizeFiles asks for one-line summaries of each file in a large diff,
+// sending the files in batches that fit the budget
+func summarizeFiles(ctx context.Context, apiKey string, diff string, budget int) (string, error) {
+	instruction, err := prompts.Render("gcm_files", promptData("", "", nil))
+	if err != nil {
+		return "", err
+	}
+
+	var summaries []string
+	for _, batch := range batchFiles(splitDiff(diff), budget) {
+		summary, err := generateText(ctx, apiKey, "gcm-files", templateQuery("gcm_files", batch), instruction+"\n"+batch)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, strings.TrimSpace(summary))
+	}
+	return strings.Join(summaries, "\n"), nil
+}
+
+// splitDiff breaks a git diff into one section per file
+func splitDiff(diff string) []string {
+	starts := diffFileHeader.FindAllStringIndex(diff, -1)
+	if len(starts) == 0 {
+		return []string{diff}
+	}
+
+	files := make([]string, 0, len(starts))
+	for i, start := range starts {
+		end := len(diff)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		files = append(files, diff[start[0]:end])
+	}
+	return files
+}
+
+// batchFiles groups file sections into batches of at most budget bytes,
+// truncating any single file that is larger on its own
+func batchFiles(files []string, budget int) []string {
+	var batches []string
+	var current strings.Builder
+
+	for _, file := range files {
+		if len(file) > budget {
+			file = file[:budget] + "\n… [diff truncated] …\n"
+		}
+		if current.Len() > 0 && current.Len()+len(file) > budget {
+			batches = append(batches, current.String())
+			current.Reset()
+		}
+		current.WriteString(file)
+	}
+
+	if current.Len() > 0 {
+		batches = append(batches, current.String())
+	}
+	return batches
+}
+
+// cleanCommitMessage strips fences and quotes the model may have added
+func cleanCommitMessage(message string) string {
+	message = strings.TrimSpace(message)
+	if match := codeFencePattern.FindStringSubmatch(message); match != nil {
+		message = strings.TrimSpace(match[1])
+	}
+	if len(message) >= 2 && message[0] == '"' && message[len(message)-1] == '"' && !strings.Contains(message, "\n") {
+		message = message[1 : len(message)-1]
+	}
+	return message
+}
@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+const defaultChatSystem = `You are a helpful command-line assistant chatting with a user inside the GO-TERM terminal.
+Answer concisely and accurately. Use earlier turns of the conversation to resolve follow-up questions.
+The output will be printed in a terminal, so keep formatting simple.
+platform %s`
+
+var conversationNamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Message is a single turn in a conversation
+type Message struct {
+	Role        string       `json:"role"`
+	Text        string       `json:"text"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Time        time.Time    `json:"time"`
+}
+
+// Conversation holds a multi-turn chat with the model
+type Conversation struct {
+	Name     string    `json:"name,omitempty"`
+	System   string    `json:"system"`
+	Messages []Message `json:"messages"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// ConversationInfo summarizes a saved conversation
+type ConversationInfo struct {
+	Name     string
+	Messages int
+	Updated  time.Time
+}
+
+// NewConversation starts an empty conversation with the default system prompt
+func NewConversation() *Conversation {
+	now := time.Now()
+	return &Conversation{
+		System:  fmt.Sprintf(defaultChatSystem, runtime.GOOS),
+		Created: now,
+		Updated: now,
+	}
+}
+
+// Send adds the user's message and its attachments to the conversation
+// and returns the model's reply
+func (c *Conversation) Send(ctx context.Context, text string, attachments []Attachment) (string, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	c.Messages = append(c.Messages, Message{Role: "user", Text: text, Attachments: attachments, Time: time.Now()})
+
+	reply, err := sendGeminiRequest(ctx, apiKey, aiCall{kind: "chat", uncached: true, request: c.request()})
+	if err == nil && isNoAnswer(reply) {
+		err = noAnswer("the model declined to answer")
+	}
+	if err != nil {
+		// Drop the unanswered turn so the history stays well-formed
+		c.Messages = c.Messages[:len(c.Messages)-1]
+		return "", err
+	}
+
+	c.Messages = append(c.Messages, Message{Role: "model", Text: reply, Time: time.Now()})
+	c.Updated = time.Now()
+
+	return reply, nil
+}
+
+// Clear removes all turns, keeping the system prompt
+func (c *Conversation) Clear() {
+	c.Messages = nil
+	c.Updated = time.Now()
+}
+
+// request builds the Gemini request carrying the full conversation history
+func (c *Conversation) request() GeminiRequest {
+	request := GeminiRequest{}
+	if c.System != "" {
+		request.SystemInstruction = &Content{Parts: []Part{{Text: c.System}}}
+	}
+
+	for _, msg := range c.Messages {
+		request.Contents = append(request.Contents, Content{
+			Role:  msg.Role,
+			Parts: []Part{{Text: formatAttachments(msg.Attachments) + msg.Text}},
+		})
+	}
+
+	return request
+}
+
+// getConversationDir returns the directory where conversations are stored
+func getConversationDir() (string, error) {
+	dir := filepath.Join(config.GetConfigDir(), "conversations")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// conversationPath returns the file path for a conversation name
+func conversationPath(name string) (string, error) {
+	name = conversationNamePattern.ReplaceAllString(strings.TrimSpace(name), "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		return "", errors.New("conversation name cannot be empty")
+	}
+
+	dir, err := getConversationDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveConversation writes the conversation to the config directory under name
+func SaveConversation(c *Conversation, name string) error {
+	path, err := conversationPath(name)
+	if err != nil {
+		return err
+	}
+
+	c.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadConversation reads a saved conversation by name
+func LoadConversation(name string) (*Conversation, error) {
+	path, err := conversationPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("conversation %q not found", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// ListConversations returns saved conversations, most recently updated first
+func ListConversations() ([]ConversationInfo, error) {
+	dir, err := getConversationDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []ConversationInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		c, err := LoadConversation(name)
+		if err != nil {
+			continue
+		}
+
+		infos = append(infos, ConversationInfo{
+			Name:     name,
+			Messages: len(c.Messages),
+			Updated:  c.Updated,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Updated.After(infos[j].Updated)
+	})
+
+	return infos, nil
+}
@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+)
+
+// descriptionsSchema describes the JSON object the model returns for
+// history describe
+var descriptionsSchema = &Schema{
+	Type: "OBJECT",
+	Properties: map[string]*Schema{
+		"descriptions": {Type: "ARRAY", Items: &Schema{
+			Type: "OBJECT",
+			Properties: map[string]*Schema{
+				"index":       {Type: "INTEGER"},
+				"description": {Type: "STRING"},
+			},
+			Required: []string{"index", "description"},
+		}},
+	},
+	Required: []string{"descriptions"},
+}
+
+// DescribeCommands asks for a short description of each command, for the
+// history search index. Commands the model skips are left out of the result.
+func DescribeCommands(ctx context.Context, commands []string) (map[string]string, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var listing strings.Builder
+	for i, command := range commands {
+		fmt.Fprintf(&listing, "%d. %s\n", i, command)
+	}
+	instruction, err := prompts.Render("history_describe", promptData("", "", nil))
+	if err != nil {
+		return nil, err
+	}
+	prompt := instruction + "\nCommands:\n" + listing.String()
+
+	call := aiCall{
+		kind:  "history",
+		query: templateQuery("history_describe", listing.String()),
+		request: GeminiRequest{
+			Contents: []Content{
+				{Role: "user", Parts: []Part{{Text: prompt}}},
+			},
+			GenerationConfig: &GenerationConfig{
+				ResponseMimeType: "application/json",
+				ResponseSchema:   descriptionsSchema,
+			},
+		},
+	}
+
+	responseText, err := sendGeminiRequest(ctx, apiKey, call)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(responseText)
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	var response struct {
+		Descriptions []struct {
+			Index       int    `json:"index"`
+			Description string `json:"description"`
+		} `json:"descriptions"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		return nil, fmt.Errorf("invalid response from model: %w", err)
+	}
+
+	descriptions := map[string]string{}
+	for _, d := range response.Descriptions {
+		description := strings.TrimSpace(d.Description)
+		if d.Index < 0 || d.Index >= len(commands) || description == "" || isNoAnswer(description) {
+			continue
+		}
+		descriptions[commands[d.Index]] = description
+	}
+	return descriptions, nil
+}
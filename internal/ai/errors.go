@@ -0,0 +1,247 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/logger"
+)
+
+// Error kinds returned by the Gemini API; match them with errors.Is
+var (
+	ErrAuth        = errors.New("API key invalid")
+	ErrQuota       = errors.New("quota exhausted")
+	ErrRateLimited = errors.New("rate limited")
+	ErrBlocked     = errors.New("blocked by safety filters")
+	ErrServer      = errors.New("server error")
+	ErrTimeout     = errors.New("request timed out")
+	ErrBadRequest  = errors.New("bad request")
+	ErrNoAnswer    = errors.New("no answer")
+)
+
+// noAnswerToken is what the prompts ask the model to reply with when it
+// cannot or will not answer
+const noAnswerToken = "3d8a19a704"
+
+const (
+	maxAttempts = 4
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 8 * time.Second
+	// maxRetryAfter is the longest server-requested wait we sit through
+	maxRetryAfter = 30 * time.Second
+)
+
+// APIError is a failed call to the model
+type APIError struct {
+	Kind        error
+	Status      int
+	Message     string
+	BlockReason string
+	RetryAfter  time.Duration
+	Err         error
+}
+
+func (e *APIError) Error() string {
+	msg := e.Kind.Error()
+	if e.BlockReason != "" {
+		msg += " (" + e.BlockReason + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap exposes both the kind and the underlying cause
+func (e *APIError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// retryable reports whether the call may succeed if sent again
+func (e *APIError) retryable() bool {
+	return e.Kind == ErrRateLimited || e.Kind == ErrServer
+}
+
+// apiErrorBody is the error object Gemini returns with non-200 responses
+type apiErrorBody struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type       string `json:"@type"`
+			Reason     string `json:"reason"`
+			RetryDelay string `json:"retryDelay"`
+			Violations []struct {
+				QuotaID string `json:"quotaId"`
+			} `json:"violations"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// parseAPIError turns a non-200 response into an APIError
+func parseAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{Status: resp.StatusCode}
+
+	var parsed apiErrorBody
+	dailyQuota := false
+	keyInvalid := false
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Message = parsed.Error.Message
+		for _, detail := range parsed.Error.Details {
+			if detail.Reason == "API_KEY_INVALID" {
+				keyInvalid = true
+			}
+			if detail.RetryDelay != "" {
+				if delay, err := time.ParseDuration(detail.RetryDelay); err == nil {
+					apiErr.RetryAfter = delay
+				}
+			}
+			for _, v := range detail.Violations {
+				if strings.Contains(v.QuotaID, "PerDay") {
+					dailyQuota = true
+				}
+			}
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = describeStatus(resp.StatusCode)
+	}
+
+	if delay := parseRetryAfter(resp.Header.Get("Retry-After")); delay > 0 {
+		apiErr.RetryAfter = delay
+	}
+
+	switch {
+	case keyInvalid || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		apiErr.Kind = ErrAuth
+	case resp.StatusCode == http.StatusTooManyRequests && dailyQuota:
+		apiErr.Kind = ErrQuota
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.Kind = ErrRateLimited
+	case resp.StatusCode == http.StatusGatewayTimeout:
+		apiErr.Kind = ErrTimeout
+	case resp.StatusCode >= 500:
+		apiErr.Kind = ErrServer
+	default:
+		apiErr.Kind = ErrBadRequest
+	}
+
+	return apiErr
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
+
+// transportError classifies an error from the HTTP client itself
+func transportError(err error) error {
+	var urlErr *url.Error
+	if errors.Is(err, ErrNoFixture) && errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &urlErr) && urlErr.Timeout()) {
+		return &APIError{Kind: ErrTimeout, Err: err}
+	}
+	return err
+}
+
+// backoff returns how long to wait before the given retry attempt (1-based),
+// using jittered exponential backoff unless the server asked for a delay
+func backoff(attempt int, apiErr *APIError) time.Duration {
+	if apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+
+	delay := baseBackoff << (attempt - 1)
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	// Equal jitter: half fixed, half random, so clients don't retry in lockstep
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// withRetry calls fn until it succeeds, fails permanently, or runs out of
+// attempts. The result of the last attempt is returned even when it failed.
+func withRetry[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil {
+			return result, nil
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.retryable() || attempt >= maxAttempts {
+			return result, err
+		}
+
+		wait := backoff(attempt, apiErr)
+		if wait > maxRetryAfter {
+			return result, err
+		}
+		logger.Info("Gemini %s (status %d), retrying in %s (attempt %d/%d)",
+			apiErr.Kind, apiErr.Status, wait.Round(time.Millisecond), attempt+1, maxAttempts)
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// blockedError reports a prompt or answer withheld by safety filters
+func blockedError(reason string) *APIError {
+	return &APIError{Kind: ErrBlocked, Status: http.StatusOK, BlockReason: reason}
+}
+
+// noAnswer returns ErrNoAnswer with the reason the model gave nothing usable
+func noAnswer(reason string) error {
+	return fmt.Errorf("%w: %s", ErrNoAnswer, reason)
+}
+
+// isNoAnswer reports whether a reply is the no-answer token, tolerating the
+// quotes, code fences, labels and punctuation models sometimes wrap it in
+func isNoAnswer(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+	text = strings.Trim(text, " \t\r\n`'\"*.,:;!")
+	for _, label := range []string{"uuid:", "uuid", "command:"} {
+		text = strings.TrimSpace(strings.TrimPrefix(text, label))
+	}
+	return strings.Trim(text, " `'\"*.,:;!") == noAnswerToken
+}
+
+// describeStatus is used when an error response has no readable message
+func describeStatus(status int) string {
+	return fmt.Sprintf("status %d %s", status, http.StatusText(status))
+}
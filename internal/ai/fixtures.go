@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNoFixture is returned in replay mode for a request that was never recorded
+var ErrNoFixture = errors.New("no recorded fixture for this request")
+
+// FixtureMode selects what the fixture transport does with requests
+type FixtureMode int
+
+const (
+	// FixturesRecord sends requests to the provider and saves the answers
+	FixturesRecord FixtureMode = iota + 1
+
+	// FixturesReplay answers from saved fixtures without touching the network
+	FixturesReplay
+)
+
+// httpTransport carries every request to the provider; nil means
+// http.DefaultTransport
+var httpTransport http.RoundTripper
+
+// replaying is set while answers come from fixtures, so no API key is needed
+var replaying bool
+
+// fixture is one recorded exchange. Bodies are kept as JSON where they are
+// JSON so fixture changes read well in a diff.
+type fixture struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Request  json.RawMessage `json:"request"`
+	Status   int             `json:"status"`
+	Type     string          `json:"content_type,omitempty"`
+	Response json.RawMessage `json:"response"`
+}
+
+// fixtureTransport records or replays provider exchanges in a directory,
+// one file per distinct request
+type fixtureTransport struct {
+	dir  string
+	mode FixtureMode
+	mu   sync.Mutex
+}
+
+// UseFixtures routes all provider requests through fixture files in dir,
+// recording new ones or replaying saved ones
+func UseFixtures(dir string, mode FixtureMode) {
+	httpTransport = &fixtureTransport{dir: dir, mode: mode}
+	replaying = mode == FixturesReplay
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	key := fixtureKey(req.Method, req.URL.String(), body)
+	path := filepath.Join(t.dir, key+".json")
+
+	if t.mode == FixturesReplay {
+		return t.replay(req, path, key)
+	}
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || resp.StatusCode/100 != 2 {
+		// Failures are not worth replaying
+		return resp, err
+	}
+
+	answer, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(answer))
+
+	if err := t.save(path, fixture{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Request:  asJSON(body),
+		Status:   resp.StatusCode,
+		Type:     resp.Header.Get("Content-Type"),
+		Response: asJSON(answer),
+	}); err != nil {
+		return nil, fmt.Errorf("saving fixture: %w", err)
+	}
+	return resp, nil
+}
+
+// replay answers a request from its fixture file
+func (t *fixtureTransport) replay(req *http.Request, path, key string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w (%s); record it with --record", ErrNoFixture, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", key, err)
+	}
+
+	answer := []byte(f.Response)
+	var text string
+	if json.Unmarshal(f.Response, &text) == nil {
+		answer = []byte(text)
+	}
+
+	header := http.Header{}
+	if f.Type != "" {
+		header.Set("Content-Type", f.Type)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(answer)),
+		ContentLength: int64(len(answer)),
+		Request:       req,
+	}, nil
+}
+
+// save writes a fixture file
+func (t *fixtureTransport) save(path string, f fixture) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// fixtureKey identifies a request by its method, URL and body. The API key
+// travels in a header, so it is neither part of the key nor stored.
+func fixtureKey(method, url string, body []byte) string {
+	var compact bytes.Buffer
+	if json.Compact(&compact, body) == nil {
+		body = compact.Bytes()
+	}
+	sum := sha256.Sum256([]byte(method + " " + url + "\n" + string(body)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// asJSON keeps a JSON body as is and stores anything else, such as a
+// stream of server-sent events, as a JSON string
+func asJSON(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(strings.ToValidUTF8(string(body), "�"))
+	return quoted
+}
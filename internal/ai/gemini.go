@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,14 +25,16 @@ type GeminiClient struct {
 // NewGeminiClient creates a new Gemini client
 func NewGeminiClient() (*GeminiClient, error) {
 	cfg := config.GetConfig()
-	if cfg.GeminiAPIKey == "" {
-		return nil, errors.New("API key not set")
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
 	}
 
 	client := &GeminiClient{
-		apiKey: cfg.GeminiAPIKey,
+		apiKey: apiKey,
 		httpClient: &http.Client{
-			Timeout: time.Duration(cfg.DefaultTimeout) * time.Second,
+			Timeout:   time.Duration(cfg.DefaultTimeout) * time.Second,
+			Transport: httpTransport,
 		},
 		config: cfg,
 	}
@@ -47,30 +48,19 @@ func (c *GeminiClient) StreamCompletion(
 	prompt string,
 	callback func(chunk string, done bool) error,
 ) error {
-	url := fmt.Sprintf(
-		"https://generativelanguage.googleapis.com/v1/models/gemini-1.5-flash:streamGenerateContent?key=%s",
-		c.apiKey,
-	)
+	url := fmt.Sprintf("%s/%s:streamGenerateContent", geminiBaseURL, c.config.Model)
 
 	// Build request payload
 	request := GeminiRequest{
-		Contents: []struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		}{
-			{
-				Parts: []struct {
-					Text string `json:"text"`
-				}{
-					{
-						Text: prompt,
-					},
-				},
-			},
+		Contents: []Content{
+			{Role: "user", Parts: []Part{{Text: prompt}}},
 		},
 	}
 
+	if _, err := redactRequest(&request); err != nil {
+		return err
+	}
+
 	requestData, err := json.Marshal(request)
 	if err != nil {
 		return err
@@ -82,21 +72,25 @@ func (c *GeminiClient) StreamCompletion(
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", c.apiKey)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		return transportError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		logger.Error("Gemini API error: %d %s", resp.StatusCode, string(body))
-		return fmt.Errorf("API returned status code %d", resp.StatusCode)
+		apiErr := parseAPIError(resp, body)
+		logger.Error("Gemini API error: %d %s", resp.StatusCode, apiErr.Message)
+		return apiErr
 	}
 
 	// Handle streaming response
+	start := time.Now()
 	scanner := bufio.NewScanner(resp.Body)
+	var usage tokenUsage
 
 	var responseBuilder strings.Builder
 	for scanner.Scan() {
@@ -116,6 +110,7 @@ func (c *GeminiClient) StreamCompletion(
 					} `json:"parts"`
 				} `json:"content"`
 			} `json:"candidates"`
+			UsageMetadata tokenUsage `json:"usageMetadata"`
 		}
 
 		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
@@ -123,6 +118,11 @@ func (c *GeminiClient) StreamCompletion(
 			continue
 		}
 
+		// Each chunk carries the running totals; keep the latest
+		if chunk.UsageMetadata.TotalTokens > 0 {
+			usage = chunk.UsageMetadata
+		}
+
 		if len(chunk.Candidates) == 0 || len(chunk.Candidates[0].Content.Parts) == 0 {
 			continue
 		}
@@ -136,6 +136,12 @@ func (c *GeminiClient) StreamCompletion(
 		}
 	}
 
+	if usage.TotalTokens > 0 {
+		if err := recordUsage("stream", c.config.Model, usage, time.Since(start)); err != nil {
+			logger.Error("Failed to record usage: %v", err)
+		}
+	}
+
 	// Signal completion
 	return callback("", true)
 }
@@ -153,9 +159,12 @@ func (c *GeminiClient) Complete(ctx context.Context, prompt string) (string, err
 		return "", err
 	}
 
-	response := responseBuilder.String()
-	if response == "" || response == "3d8a19a704" {
-		return "3d8a19a704", nil
+	response := strings.TrimSpace(responseBuilder.String())
+	if response == "" {
+		return "", noAnswer("the model returned an empty response")
+	}
+	if isNoAnswer(response) {
+		return "", noAnswer("the model declined to answer")
 	}
 
 	return response, nil
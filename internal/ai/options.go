@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"github/0PrashantYadav0/GO-TERM/internal/redact"
+)
+
+// ErrCancelled is returned when the user declines to send a previewed request
+var ErrCancelled = errors.New("request cancelled")
+
+// RequestOptions adjusts how the AI requests made with a context behave
+type RequestOptions struct {
+	// Preview is shown the exact redacted payload before it is sent. Returning
+	// false cancels the request.
+	Preview func(payload string, findings []redact.Finding) bool
+
+	// OverLimit is called with a warning when today's usage has passed a
+	// configured soft limit. Returning false cancels the request.
+	OverLimit func(warning string) bool
+
+	// NoCache neither reads nor writes the response cache
+	NoCache bool
+
+	// Refresh skips cached answers but stores the new one
+	Refresh bool
+
+	// Info, when set, is filled in with how the request was answered
+	Info *CallInfo
+}
+
+// CallInfo reports how a request was answered
+type CallInfo struct {
+	// Cached is true when the answer came from the response cache
+	Cached bool
+
+	// Offline is true when the network was unreachable
+	Offline bool
+
+	// MatchedQuery is the earlier query whose answer was reused when the
+	// offline fallback found no exact match
+	MatchedQuery string
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a context carrying options for AI requests
+func WithOptions(ctx context.Context, opts RequestOptions) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// optionsFrom returns the options stored in ctx, if any
+func optionsFrom(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(optionsKey{}).(RequestOptions)
+	return opts
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+)
+
+// maxDiffLines bounds the line diff, which is quadratic in the output size
+const maxDiffLines = 2000
+
+// ExplainOutput explains the captured output of a command, answering
+// question if one is given
+func ExplainOutput(ctx context.Context, run *CommandLog, question string) (string, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	instruction, err := prompts.Render("hx", promptData(question, run.Command.Raw, nil))
+	if err != nil {
+		return "", err
+	}
+	runs := describeRun("Command", run)
+
+	return generateText(ctx, apiKey, "hx", templateQuery("hx", question+"\n"+runs), instruction+"\n"+runs)
+}
+
+// ExplainOutputDiff explains how the output of a command changed between
+// two runs
+func ExplainOutputDiff(ctx context.Context, previous, latest *CommandLog, question string) (string, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	instruction, err := prompts.Render("hx_diff", promptData(question, latest.Command.Raw, nil))
+	if err != nil {
+		return "", err
+	}
+	runs := describeRun("Earlier run", previous) + "\n" + describeRun("Latest run", latest)
+	if diff, ok := diffLines(previous.Output.Stdout+previous.Output.Stderr, latest.Output.Stdout+latest.Output.Stderr); ok {
+		runs += "\nLine diff:\n" + orNone(diff) + "\n"
+	}
+
+	return generateText(ctx, apiKey, "hx", templateQuery("hx_diff", question+"\n"+runs), instruction+"\n"+runs)
+}
+
+// describeRun formats a command and its captured output for a prompt
+func describeRun(label string, run *CommandLog) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s): %s\n", label, run.Timestamp, run.Command.Raw)
+	fmt.Fprintf(&b, "exit code: %d\n", run.Output.ExitCode)
+	fmt.Fprintf(&b, "stdout:\n%s\n", orNone(run.Output.Stdout))
+	fmt.Fprintf(&b, "stderr:\n%s\n", orNone(run.Output.Stderr))
+	return b.String()
+}
+
+// diffLines returns the changed lines between two outputs, prefixed with
+// "-" or "+". It gives up on outputs too long to compare cheaply.
+func diffLines(before, after string) (string, bool) {
+	a := strings.Split(strings.TrimRight(before, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(after, "\n"), "\n")
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return "", false
+	}
+
+	// Longest common subsequence, filled from the end so the walk below
+	// can go forwards
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+
+	return out.String(), true
+}
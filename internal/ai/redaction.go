@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"github/0PrashantYadav0/GO-TERM/internal/redact"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"github/0PrashantYadav0/GO-TERM/pkg/logger"
+)
+
+// newRedactor builds the configured redactor, or returns nil when redaction
+// is off
+func newRedactor() (*redact.Redactor, error) {
+	cfg := config.GetConfig().Redaction
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return redact.New(cfg.Patterns, cfg.Entropy)
+}
+
+// redactText strips secrets from a single piece of text
+func redactText(text string) (string, error) {
+	redactor, err := newRedactor()
+	if err != nil || redactor == nil {
+		return text, err
+	}
+	text, _ = redactor.Redact(text)
+	return text, nil
+}
+
+// redactRequest strips secrets from every piece of text in the request
+func redactRequest(request *GeminiRequest) ([]redact.Finding, error) {
+	redactor, err := newRedactor()
+	if err != nil || redactor == nil {
+		return nil, err
+	}
+
+	var findings []redact.Finding
+	redactContent := func(content *Content) {
+		for i := range content.Parts {
+			text, found := redactor.Redact(content.Parts[i].Text)
+			content.Parts[i].Text = text
+			findings = append(findings, found...)
+		}
+	}
+
+	if request.SystemInstruction != nil {
+		system := *request.SystemInstruction
+		system.Parts = append([]Part{}, system.Parts...)
+		redactContent(&system)
+		request.SystemInstruction = &system
+	}
+
+	// Copy the contents so the caller's conversation history keeps its text
+	contents := make([]Content, len(request.Contents))
+	for i, content := range request.Contents {
+		content.Parts = append([]Part{}, content.Parts...)
+		redactContent(&content)
+		contents[i] = content
+	}
+	request.Contents = contents
+
+	if len(findings) > 0 {
+		logger.Info("Redacted %s from AI request", redact.Summary(findings))
+	}
+
+	return findings, nil
+}
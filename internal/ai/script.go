@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/lint"
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+)
+
+// scriptSchema describes the JSON object the model returns for hscript
+var scriptSchema = &Schema{
+	Type: "OBJECT",
+	Properties: map[string]*Schema{
+		"script":      {Type: "STRING", Description: "The complete script, one command per line"},
+		"explanation": {Type: "STRING"},
+	},
+	Required: []string{"script"},
+}
+
+// Script is a generated script with the model's note on it
+type Script struct {
+	Text        string `json:"script"`
+	Explanation string `json:"explanation"`
+}
+
+// GenerateScript writes a bash or GO-TERM script for a task. To fix a
+// script, pass it as previous with the problems found in it.
+func GenerateScript(ctx context.Context, description, kind, previous string, problems []string) (*Script, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	name := "hscript"
+	if kind == lint.GoTerm {
+		name = "hscript_goterm"
+	}
+	data := promptData(description, description, nil)
+	instruction, err := prompts.Render(name, data)
+	if err != nil {
+		return nil, err
+	}
+	response, err := prompts.Render("hscript_response", data)
+	if err != nil {
+		return nil, err
+	}
+	prompt := instruction + response + "\n\nTask:\n" + description + "\n"
+	if previous != "" {
+		prompt += "\nFix these problems in the script below and change nothing else:\n- " + strings.Join(problems, "\n- ") + "\n\nScript:\n" + previous + "\n"
+	}
+
+	call := aiCall{
+		kind:  "hscript",
+		query: templateQuery("hscript_response", templateQuery(name, kind+"\n"+description+"\n"+previous+strings.Join(problems, "\n"))),
+		request: GeminiRequest{
+			Contents: []Content{
+				{Role: "user", Parts: []Part{{Text: prompt}}},
+			},
+			GenerationConfig: &GenerationConfig{
+				ResponseMimeType: "application/json",
+				ResponseSchema:   scriptSchema,
+			},
+		},
+	}
+
+	responseText, err := sendGeminiRequest(ctx, apiKey, call)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(responseText)
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	var script Script
+	if err := json.Unmarshal([]byte(text), &script); err != nil {
+		return nil, fmt.Errorf("invalid response from model: %w", err)
+	}
+	if match := codeFencePattern.FindStringSubmatch(script.Text); match != nil {
+		script.Text = match[1]
+	}
+	script.Text = strings.TrimSpace(script.Text) + "\n"
+	if isNoAnswer(script.Text) || strings.TrimSpace(script.Text) == "" {
+		return nil, noAnswer("the model couldn't write a script for that")
+	}
+	return &script, nil
+}
@@ -0,0 +1,213 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Suggestion is a structured command suggestion returned by the model
+type Suggestion struct {
+	Command      string   `json:"command"`
+	Explanation  string   `json:"explanation"`
+	Confidence   float64  `json:"confidence"`
+	RiskLevel    string   `json:"risk_level"`
+	RequiresSudo bool     `json:"requires_sudo"`
+	Alternatives []string `json:"alternatives"`
+
+	// Missing lists executables in Command that are not on PATH
+	Missing []string `json:"-"`
+}
+
+// Schema is the subset of the OpenAPI schema accepted by Gemini's responseSchema
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+}
+
+// GenerationConfig controls the format of the model's output
+type GenerationConfig struct {
+	ResponseMimeType string  `json:"responseMimeType,omitempty"`
+	ResponseSchema   *Schema `json:"responseSchema,omitempty"`
+}
+
+// suggestionSchema describes the JSON object the model must return for hp/hm
+var suggestionSchema = &Schema{
+	Type: "OBJECT",
+	Properties: map[string]*Schema{
+		"command":       {Type: "STRING", Description: "The exact single-line shell command to run, without code fences"},
+		"explanation":   {Type: "STRING", Description: "One short sentence on what the command does"},
+		"confidence":    {Type: "NUMBER", Description: "Confidence from 0 to 1 that the command is correct"},
+		"risk_level":    {Type: "STRING", Enum: []string{"low", "medium", "high"}},
+		"requires_sudo": {Type: "BOOLEAN"},
+		"alternatives":  {Type: "ARRAY", Items: &Schema{Type: "STRING"}},
+	},
+	Required: []string{"command", "explanation", "confidence", "risk_level", "requires_sudo"},
+}
+
+// candidatesSchema describes the JSON object holding several suggestions
+var candidatesSchema = &Schema{
+	Type: "OBJECT",
+	Properties: map[string]*Schema{
+		"candidates": {Type: "ARRAY", Items: suggestionSchema},
+	},
+	Required: []string{"candidates"},
+}
+
+// shellBuiltins are command words that never live on PATH
+var shellBuiltins = map[string]bool{
+	"cd": true, "export": true, "source": true, ".": true, "alias": true, "unset": true,
+	"set": true, "for": true, "while": true, "if": true, "then": true, "do": true,
+	"done": true, "fi": true, "case": true, "esac": true, "function": true, "eval": true,
+	"exec": true, "read": true, "shift": true, "return": true, "exit": true, "{": true,
+	"}": true, "[[": true, "test": true, "[": true, "echo": true, "printf": true,
+	"pwd": true, "type": true, "command": true, "builtin": true, "ulimit": true, "umask": true,
+}
+
+// commandWrappers run the word that follows them as the real command
+var commandWrappers = map[string]bool{
+	"sudo": true, "env": true, "time": true, "nohup": true, "nice": true, "doas": true,
+}
+
+var commandSeparatorPattern = regexp.MustCompile(`\|\||&&|[|;&]|\$\(|` + "`")
+
+// executables returns the program names invoked by a shell command line
+func executables(command string) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	for _, segment := range commandSeparatorPattern.Split(command, -1) {
+		for _, word := range strings.Fields(segment) {
+			word = strings.Trim(word, "()")
+			if word == "" || strings.Contains(word, "=") || strings.HasPrefix(word, "-") {
+				continue
+			}
+			if commandWrappers[word] {
+				continue
+			}
+			if !shellBuiltins[word] && !seen[word] {
+				seen[word] = true
+				names = append(names, word)
+			}
+			break
+		}
+	}
+
+	return names
+}
+
+// flagMissing records which executables in the suggestion are not installed
+func flagMissing(suggestion *Suggestion) {
+	suggestion.Missing = nil
+	for _, name := range executables(suggestion.Command) {
+		if _, err := exec.LookPath(name); err != nil {
+			suggestion.Missing = append(suggestion.Missing, name)
+		}
+	}
+}
+
+var codeFencePattern = regexp.MustCompile("(?s)^```[a-zA-Z]*\\s*(.*?)\\s*```$")
+
+// parseSuggestion decodes and validates the model's JSON response
+func parseSuggestion(responseText string) (*Suggestion, error) {
+	text := strings.TrimSpace(responseText)
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	var suggestion Suggestion
+	if err := json.Unmarshal([]byte(text), &suggestion); err != nil {
+		return nil, fmt.Errorf("invalid response from model: %w", err)
+	}
+
+	suggestion.Command = cleanCommand(suggestion.Command)
+	if suggestion.Command == "" {
+		return nil, errors.New("invalid response from model: empty command")
+	}
+	if isNoAnswer(suggestion.Command) {
+		return nil, noAnswer("the model was not confident enough to suggest a command")
+	}
+
+	switch strings.ToLower(suggestion.RiskLevel) {
+	case "low", "medium", "high":
+		suggestion.RiskLevel = strings.ToLower(suggestion.RiskLevel)
+	default:
+		suggestion.RiskLevel = "medium"
+	}
+
+	if suggestion.Confidence < 0 {
+		suggestion.Confidence = 0
+	} else if suggestion.Confidence > 1 {
+		suggestion.Confidence = 1
+	}
+
+	var alternatives []string
+	for _, alt := range suggestion.Alternatives {
+		if alt = cleanCommand(alt); alt != "" && alt != suggestion.Command {
+			alternatives = append(alternatives, alt)
+		}
+	}
+	suggestion.Alternatives = alternatives
+	flagMissing(&suggestion)
+
+	return &suggestion, nil
+}
+
+// parseCandidates decodes a list of suggestions, dropping invalid entries
+func parseCandidates(responseText string) ([]*Suggestion, error) {
+	text := strings.TrimSpace(responseText)
+	if match := codeFencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	var response struct {
+		Candidates []json.RawMessage `json:"candidates"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		return nil, fmt.Errorf("invalid response from model: %w", err)
+	}
+
+	var candidates []*Suggestion
+	var lastErr error
+	seen := map[string]bool{}
+	for _, raw := range response.Candidates {
+		suggestion, err := parseSuggestion(string(raw))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if seen[suggestion.Command] {
+			continue
+		}
+		seen[suggestion.Command] = true
+		candidates = append(candidates, suggestion)
+	}
+
+	if len(candidates) == 0 {
+		if errors.Is(lastErr, ErrNoAnswer) {
+			return nil, lastErr
+		}
+		return nil, errors.New("invalid response from model: no candidates")
+	}
+
+	return candidates, nil
+}
+
+// cleanCommand strips code fences, backticks and prompt markers the model
+// sometimes wraps around a command
+func cleanCommand(command string) string {
+	command = strings.TrimSpace(command)
+	if match := codeFencePattern.FindStringSubmatch(command); match != nil {
+		command = match[1]
+	}
+	command = strings.Trim(command, "`")
+	command = strings.TrimPrefix(command, "$ ")
+	return strings.TrimSpace(command)
+}
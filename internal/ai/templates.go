@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/environment"
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/internal/translate"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"github/0PrashantYadav0/GO-TERM/pkg/utils"
+)
+
+// Output modes of user-defined AI commands
+const (
+	OutputText    = "text"
+	OutputCopy    = "copy"
+	OutputCommand = "command"
+)
+
+// promptData gathers what prompt templates can refer to. Tools named in
+// hint have their versions included in the environment block.
+func promptData(input, hint string, failed *CommandLog) prompts.Data {
+	data := prompts.Data{
+		Input:    input,
+		Platform: runtime.GOOS,
+		Shell:    utils.GetShellName(),
+		Context:  environment.Default.Collect(hint),
+		History:  environment.Default.History(config.GetConfig().Context.HistoryEntries),
+		NoAnswer: noAnswerToken,
+	}
+
+	if dir, err := os.Getwd(); err == nil {
+		data.Cwd = dir
+		if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(dir, home) {
+			data.Cwd = "~" + dir[len(home):]
+		}
+	}
+
+	if failed == nil {
+		failed, _ = getLastCommandLog()
+	}
+	if failed != nil {
+		// The username adds nothing and shouldn't leave the machine
+		entry := *failed
+		entry.Metadata.User = ""
+		if log, err := json.Marshal(entry); err == nil {
+			data.ErrorLog = string(log)
+		}
+	}
+
+	return data
+}
+
+// templateQuery adds the version of edited templates to a cache query, so
+// answers to an older prompt aren't reused
+func templateQuery(name, query string) string {
+	if fingerprint := prompts.Fingerprint(name); fingerprint != "" {
+		return query + "\nprompt " + fingerprint
+	}
+	return query
+}
+
+// PreviewPrompt renders a template as the AI command using it would, for
+// prompts test
+func PreviewPrompt(name, input string) (string, error) {
+	data := promptData(input, input, nil)
+	data.Count = config.GetConfig().HpCandidates
+	data.Style = commitStyles[config.GetConfig().Commit.Style]
+	data.Target = translate.Describe(translate.Current())
+	return prompts.Render(name, data)
+}
+
+// RunCustomCommand sends a user-defined command's template and returns the
+// model's text answer
+func RunCustomCommand(ctx context.Context, name, input string) (string, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return "", err
+	}
+
+	prompt, err := prompts.Render(name, promptData(input, input, nil))
+	if err != nil {
+		return "", err
+	}
+
+	return generateText(ctx, apiKey, name, templateQuery(name, input), prompt)
+}
+
+// SuggestCustomCommand sends a user-defined command's template and parses
+// the answer as a command suggestion
+func SuggestCustomCommand(ctx context.Context, name, input string) (*Suggestion, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := prompts.Render(name, promptData(input, input, nil))
+	if err != nil {
+		return nil, err
+	}
+	prompt += fmt.Sprintf("\n- Respond with a single shell command as a JSON object. If you cannot help, set \"command\" to %s.\n", noAnswerToken)
+
+	return generateSuggestion(ctx, apiKey, name, templateQuery(name, input), prompt)
+}
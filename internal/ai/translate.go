@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/internal/translate"
+)
+
+// TranslateCommand asks the model to translate a command for target,
+// starting from what the local rules made of it
+func TranslateCommand(ctx context.Context, command, target string, local translate.Result) (*Suggestion, error) {
+	apiKey, err := getApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data := promptData(command, "", nil)
+	data.Target = translate.Describe(target)
+	prompt, err := prompts.Render("htr", data)
+	if err != nil {
+		return nil, err
+	}
+	prompt += "\nCommand:\n" + command + "\n"
+	if len(local.Changes) > 0 || len(local.Gaps) > 0 {
+		prompt += "\nLocal rules rewrote it as:\n" + local.Command + "\n"
+		for _, change := range local.Changes {
+			prompt += fmt.Sprintf("- %s → %s: %s\n", change.Before, change.After, change.Note)
+		}
+		if len(local.Gaps) > 0 {
+			prompt += "They could not handle:\n- " + strings.Join(local.Gaps, "\n- ") + "\n"
+		}
+	}
+
+	return generateSuggestion(ctx, apiKey, "htr", templateQuery("htr", target+"\n"+command), prompt)
+}
@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+// tokenUsage is the usageMetadata block of a Gemini response
+type tokenUsage struct {
+	PromptTokens    int `json:"promptTokenCount"`
+	CandidateTokens int `json:"candidatesTokenCount"`
+	TotalTokens     int `json:"totalTokenCount"`
+}
+
+// usageRecord is one line of the usage journal
+type usageRecord struct {
+	Time            time.Time `json:"time"`
+	Command         string    `json:"command"`
+	Model           string    `json:"model"`
+	PromptTokens    int       `json:"prompt_tokens"`
+	CandidateTokens int       `json:"candidate_tokens"`
+	TotalTokens     int       `json:"total_tokens"`
+	LatencyMs       int64     `json:"latency_ms"`
+}
+
+// UsageRow is one group in a usage report
+type UsageRow struct {
+	Key             string
+	Calls           int
+	PromptTokens    int
+	CandidateTokens int
+	TotalTokens     int
+	Cost            float64
+	AvgLatency      time.Duration
+}
+
+// usageMu serializes appends to the journal
+var usageMu sync.Mutex
+
+// getUsagePath returns the path of the usage journal
+func getUsagePath() string {
+	return filepath.Join(config.GetConfigDir(), "usage.jsonl")
+}
+
+// usageCommand names the builtin a call came from, folding "hp-3" into "hp"
+func usageCommand(kind string) string {
+	command, _, _ := strings.Cut(kind, "-")
+	return command
+}
+
+// recordUsage appends one call to the usage journal
+func recordUsage(kind, model string, usage tokenUsage, latency time.Duration) error {
+	if !config.GetConfig().Usage.Enabled {
+		return nil
+	}
+
+	data, err := json.Marshal(usageRecord{
+		Time:            time.Now(),
+		Command:         usageCommand(kind),
+		Model:           model,
+		PromptTokens:    usage.PromptTokens,
+		CandidateTokens: usage.CandidateTokens,
+		TotalTokens:     usage.TotalTokens,
+		LatencyMs:       latency.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	file, err := os.OpenFile(getUsagePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// readUsage returns the journal entries recorded at or after since
+func readUsage(since time.Time) ([]usageRecord, error) {
+	file, err := os.Open(getUsagePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []usageRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record usageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if !record.Time.Before(since) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// priceFor returns the configured price of a model, matching versioned names
+// such as "gemini-1.5-flash-002" by their longest known prefix
+func priceFor(model string) (config.Price, bool) {
+	prices := config.GetConfig().Usage.Prices
+	if price, ok := prices[model]; ok {
+		return price, true
+	}
+
+	best := ""
+	for name := range prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return config.Price{}, false
+	}
+	return prices[best], true
+}
+
+// cost estimates the price of a call in USD
+func (r usageRecord) cost() float64 {
+	price, ok := priceFor(r.Model)
+	if !ok {
+		return 0
+	}
+	return (float64(r.PromptTokens)*price.Input + float64(r.CandidateTokens)*price.Output) / 1e6
+}
+
+// UsageReport groups the calls made since the given time by "command",
+// "model" or "day"
+func UsageReport(since time.Time, by string) ([]UsageRow, error) {
+	var keyOf func(usageRecord) string
+	switch by {
+	case "command", "":
+		keyOf = func(r usageRecord) string { return r.Command }
+	case "model":
+		keyOf = func(r usageRecord) string { return r.Model }
+	case "day":
+		keyOf = func(r usageRecord) string { return r.Time.Local().Format("2006-01-02") }
+	default:
+		return nil, fmt.Errorf("cannot group usage by %q (use command, model or day)", by)
+	}
+
+	records, err := readUsage(since)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*UsageRow)
+	latency := make(map[string]int64)
+	for _, record := range records {
+		key := keyOf(record)
+		row, ok := groups[key]
+		if !ok {
+			row = &UsageRow{Key: key}
+			groups[key] = row
+		}
+		row.Calls++
+		row.PromptTokens += record.PromptTokens
+		row.CandidateTokens += record.CandidateTokens
+		row.TotalTokens += record.TotalTokens
+		row.Cost += record.cost()
+		latency[key] += record.LatencyMs
+	}
+
+	rows := make([]UsageRow, 0, len(groups))
+	for key, row := range groups {
+		row.AvgLatency = time.Duration(latency[key]/int64(row.Calls)) * time.Millisecond
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if by == "day" {
+			return rows[i].Key < rows[j].Key
+		}
+		return rows[i].TotalTokens > rows[j].TotalTokens
+	})
+	return rows, nil
+}
+
+// checkDailyLimits returns a warning when today's usage has reached one of
+// the configured soft limits, or "" when there is none
+func checkDailyLimits() string {
+	cfg := config.GetConfig().Usage
+	if !cfg.Enabled || (cfg.DailyTokenLimit <= 0 && cfg.DailyCostLimit <= 0) {
+		return ""
+	}
+
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	records, err := readUsage(midnight)
+	if err != nil {
+		return ""
+	}
+
+	tokens, cost := 0, 0.0
+	for _, record := range records {
+		tokens += record.TotalTokens
+		cost += record.cost()
+	}
+
+	switch {
+	case cfg.DailyTokenLimit > 0 && tokens >= cfg.DailyTokenLimit:
+		return fmt.Sprintf("Today's usage is %d tokens, over the daily limit of %d", tokens, cfg.DailyTokenLimit)
+	case cfg.DailyCostLimit > 0 && cost >= cfg.DailyCostLimit:
+		return fmt.Sprintf("Today's estimated cost is $%.4f, over the daily limit of $%.2f", cost, cfg.DailyCostLimit)
+	}
+	return ""
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+// EnvVar is the environment variable checked first for the API key
+const EnvVar = "GOTERM_API_KEY"
+
+// Credential sources, in the order they are tried
+const (
+	SourceEnv     = "env"
+	SourceCommand = "command"
+	SourceKeyring = "keyring"
+	SourceFile    = "file"
+)
+
+// Secret Service attributes identifying the key in the keyring
+var keyringAttributes = []string{"service", "goterm", "account", "gemini"}
+
+// commandTimeout bounds credential_command and secret-tool calls
+const commandTimeout = 5 * time.Second
+
+var (
+	// ErrNoCredentials is returned when no source holds an API key
+	ErrNoCredentials = errors.New("API key not found")
+
+	// ErrSourceFailed is returned when a source could not be read, such as
+	// a credential_command that exits non-zero
+	ErrSourceFailed = errors.New("reading the API key failed")
+)
+
+// resolved holds the key Resolve found, so credential_command and
+// secret-tool run once per session rather than on every request
+var resolved struct {
+	sync.Mutex
+	key    string
+	source string
+}
+
+// SourceStatus describes one credential source
+type SourceStatus struct {
+	Source    string
+	Detail    string
+	Available bool
+	Key       string // masked
+	Err       error
+}
+
+// Resolve returns the API key from the first source that has one, along
+// with the name of that source. The key is kept for the rest of the
+// session. A source that fails, such as a credential_command exiting
+// non-zero, is an error rather than a reason to quietly use another key.
+func Resolve() (string, string, error) {
+	resolved.Lock()
+	defer resolved.Unlock()
+	if resolved.key != "" {
+		return resolved.key, resolved.source, nil
+	}
+
+	for _, source := range []string{SourceEnv, SourceCommand, SourceKeyring, SourceFile} {
+		key, err := lookup(source)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %s source: %v", ErrSourceFailed, source, err)
+		}
+		if key != "" {
+			resolved.key, resolved.source = key, source
+			return key, source, nil
+		}
+	}
+	return "", "", ErrNoCredentials
+}
+
+// Forget drops the key Resolve kept, so the next call reads the sources
+// again. Call it when the key or the settings pointing at it change.
+func Forget() {
+	resolved.Lock()
+	defer resolved.Unlock()
+	resolved.key, resolved.source = "", ""
+}
+
+// lookup reads the key from a single source; an empty key means the source
+// is not configured
+func lookup(source string) (string, error) {
+	switch source {
+	case SourceEnv:
+		return strings.TrimSpace(os.Getenv(EnvVar)), nil
+	case SourceCommand:
+		command := config.GetConfig().CredentialCommand
+		if command == "" {
+			return "", nil
+		}
+		out, err := run(nil, "sh", "-c", command)
+		return firstLine(out), err
+	case SourceKeyring:
+		if !KeyringAvailable() {
+			return "", nil
+		}
+		out, err := run(nil, "secret-tool", append([]string{"lookup"}, keyringAttributes...)...)
+		if err != nil {
+			// secret-tool exits non-zero when nothing is stored
+			return "", nil
+		}
+		return firstLine(out), nil
+	case SourceFile:
+		return config.GetConfig().GeminiAPIKey, nil
+	}
+	return "", fmt.Errorf("unknown credential source: %s", source)
+}
+
+// Status reports what every source currently holds
+func Status() []SourceStatus {
+	details := map[string]string{
+		SourceEnv:     "$" + EnvVar,
+		SourceCommand: "credential_command",
+		SourceKeyring: "secret-tool (Secret Service)",
+		SourceFile:    config.GetConfigPath(),
+	}
+	if command := config.GetConfig().CredentialCommand; command != "" {
+		details[SourceCommand] = "credential_command: " + command
+	}
+
+	var statuses []SourceStatus
+	for _, source := range []string{SourceEnv, SourceCommand, SourceKeyring, SourceFile} {
+		status := SourceStatus{Source: source, Detail: details[source], Available: true}
+		if source == SourceKeyring {
+			status.Available = KeyringAvailable()
+		}
+		key, err := lookup(source)
+		status.Err = err
+		if key != "" {
+			status.Key = Mask(key)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// KeyringAvailable reports whether secret-tool is installed
+func KeyringAvailable() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// Login stores the key in the keyring when one is available, or in the
+// config file otherwise. It returns the source the key was written to.
+func Login(key string, preferFile bool) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errors.New("API key cannot be empty")
+	}
+	defer Forget()
+
+	if !preferFile && KeyringAvailable() {
+		args := append([]string{"store", "--label=GO-TERM Gemini API key"}, keyringAttributes...)
+		if _, err := run(strings.NewReader(key), "secret-tool", args...); err != nil {
+			return "", fmt.Errorf("storing key in keyring: %w", err)
+		}
+		// Don't leave an older plaintext copy behind
+		if config.GetConfig().GeminiAPIKey != "" {
+			config.GetConfig().GeminiAPIKey = ""
+			if err := config.Save(); err != nil {
+				return SourceKeyring, err
+			}
+		}
+		return SourceKeyring, nil
+	}
+
+	config.GetConfig().GeminiAPIKey = key
+	if err := config.Save(); err != nil {
+		return "", err
+	}
+	return SourceFile, nil
+}
+
+// Logout removes the key from the keyring and the config file. Keys supplied
+// through the environment or a credential command are left alone.
+func Logout() ([]string, error) {
+	defer Forget()
+	var removed []string
+
+	if KeyringAvailable() {
+		if key, _ := lookup(SourceKeyring); key != "" {
+			args := append([]string{"clear"}, keyringAttributes...)
+			if _, err := run(nil, "secret-tool", args...); err != nil {
+				return removed, fmt.Errorf("clearing keyring: %w", err)
+			}
+			removed = append(removed, SourceKeyring)
+		}
+	}
+
+	if config.GetConfig().GeminiAPIKey != "" {
+		config.GetConfig().GeminiAPIKey = ""
+		if err := config.Save(); err != nil {
+			return removed, err
+		}
+		removed = append(removed, SourceFile)
+	}
+
+	return removed, nil
+}
+
+// Mask hides all but the ends of a key
+func Mask(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:4] + strings.Repeat("*", 8) + key[len(key)-4:]
+}
+
+// run executes a helper with a timeout and returns its stdout
+func run(stdin *strings.Reader, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// firstLine returns the first non-empty line of helper output
+func firstLine(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
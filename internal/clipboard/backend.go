@@ -0,0 +1,222 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+
+	"github.com/atotto/clipboard"
+)
+
+// Auto is the clipboard.backend setting that picks a backend by itself
+const Auto = "auto"
+
+// maxOSC52 is the most base64 text sent in one OSC 52 sequence. Several
+// terminals drop longer ones without a word.
+const maxOSC52 = 100000
+
+var (
+	// ErrNoBackend is returned when no backend works in this session
+	ErrNoBackend = errors.New("no clipboard backend found: install wl-clipboard, xclip or xsel, or set clipboard.backend")
+
+	// ErrWriteOnly is returned by backends that can't read the clipboard
+	ErrWriteOnly = errors.New("this clipboard backend can only write")
+)
+
+// Backend reads and writes a clipboard
+type Backend interface {
+	// Name is what clipboard.backend calls it
+	Name() string
+
+	// Available reports whether auto-detection should use it here
+	Available() bool
+
+	Read() (string, error)
+	Write(text string) error
+}
+
+// backends are tried in this order when clipboard.backend is auto
+var backends = []Backend{
+	osc52Backend{},
+	commandBackend{name: "pbcopy", copy: []string{"pbcopy"}, paste: []string{"pbpaste"}},
+	windowsBackend{},
+	commandBackend{name: "wayland", env: "WAYLAND_DISPLAY", copy: []string{"wl-copy"}, paste: []string{"wl-paste", "--no-newline"}},
+	commandBackend{name: "xclip", env: "DISPLAY", copy: []string{"xclip", "-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"}},
+	commandBackend{name: "xsel", env: "DISPLAY", copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"}},
+	commandBackend{name: "tmux", env: "TMUX", copy: []string{"tmux", "load-buffer", "-"}, paste: []string{"tmux", "save-buffer", "-"}},
+}
+
+// Backends returns every backend in auto-detection order
+func Backends() []Backend {
+	return backends
+}
+
+// Lookup finds a backend by name
+func Lookup(name string) (Backend, error) {
+	var names []string
+	for _, b := range backends {
+		if b.Name() == name {
+			return b, nil
+		}
+		names = append(names, b.Name())
+	}
+	return nil, fmt.Errorf("unknown clipboard backend %q (use %s or %s)", name, Auto, strings.Join(names, ", "))
+}
+
+// Writer returns the backend that copies: the one named in
+// clipboard.backend, or else the first available one
+func Writer() (Backend, error) {
+	if name := configured(); name != Auto {
+		return Lookup(name)
+	}
+	return detect(false)
+}
+
+// Reader returns the backend that pastes. A configured backend that can
+// only write leaves reading to auto-detection.
+func Reader() (Backend, error) {
+	if name := configured(); name != Auto {
+		b, err := Lookup(name)
+		if err != nil || !writeOnly(b) {
+			return b, err
+		}
+	}
+	return detect(true)
+}
+
+// Write copies text to the clipboard
+func Write(text string) error {
+	b, err := Writer()
+	if err != nil {
+		return err
+	}
+	return b.Write(text)
+}
+
+// Read returns the text on the clipboard
+func Read() (string, error) {
+	b, err := Reader()
+	if err != nil {
+		return "", err
+	}
+	return b.Read()
+}
+
+// configured returns the clipboard.backend setting
+func configured() string {
+	name := strings.ToLower(strings.TrimSpace(config.GetConfig().Clipboard.Backend))
+	if name == "" {
+		return Auto
+	}
+	return name
+}
+
+// detect returns the first available backend, skipping write-only ones
+// when reading
+func detect(read bool) (Backend, error) {
+	for _, b := range backends {
+		if read && writeOnly(b) {
+			continue
+		}
+		if b.Available() {
+			return b, nil
+		}
+	}
+	return nil, ErrNoBackend
+}
+
+// writeOnly reports whether a backend can't read
+func writeOnly(b Backend) bool {
+	_, ok := b.(osc52Backend)
+	return ok
+}
+
+// commandBackend copies by piping text into a program and pastes by
+// reading another's output
+type commandBackend struct {
+	name  string
+	env   string // only detected when this variable is set, e.g. DISPLAY
+	copy  []string
+	paste []string
+}
+
+func (b commandBackend) Name() string { return b.name }
+
+func (b commandBackend) Available() bool {
+	if b.env != "" && os.Getenv(b.env) == "" {
+		return false
+	}
+	_, err := exec.LookPath(b.copy[0])
+	return err == nil
+}
+
+func (b commandBackend) Read() (string, error) {
+	out, err := exec.Command(b.paste[0], b.paste[1:]...).Output()
+	return string(out), err
+}
+
+func (b commandBackend) Write(text string) error {
+	cmd := exec.Command(b.copy[0], b.copy[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// windowsBackend uses the Windows clipboard API
+type windowsBackend struct{}
+
+func (windowsBackend) Name() string { return "windows" }
+
+func (windowsBackend) Available() bool { return runtime.GOOS == "windows" }
+
+func (windowsBackend) Read() (string, error) { return clipboard.ReadAll() }
+
+func (windowsBackend) Write(text string) error { return clipboard.WriteAll(text) }
+
+// osc52Backend asks the terminal to set its clipboard with an OSC 52 escape
+// sequence, which works across SSH. The terminal can't be asked whether it
+// did, and reading is rarely allowed, so it only writes.
+type osc52Backend struct{}
+
+func (osc52Backend) Name() string { return "osc52" }
+
+// Available is only true in SSH sessions without a forwarded display, where
+// the other backends would reach the remote machine's clipboard rather than
+// the user's
+func (osc52Backend) Available() bool {
+	ssh := os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+	return ssh && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+func (osc52Backend) Read() (string, error) { return "", ErrWriteOnly }
+
+func (osc52Backend) Write(text string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	if len(encoded) > maxOSC52 {
+		return fmt.Errorf("%d bytes is too long to copy with OSC 52", len(text))
+	}
+
+	sequence := "\x1b]52;c;" + encoded + "\a"
+	switch {
+	case os.Getenv("TMUX") != "":
+		// tmux passes a sequence on to the outer terminal inside a DCS
+		// with its escapes doubled
+		sequence = "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		sequence = "\x1bP" + sequence + "\x1b\\"
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		_, err = os.Stdout.WriteString(sequence)
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString(sequence)
+	return err
+}
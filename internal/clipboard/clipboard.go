@@ -1,12 +1,9 @@
 package clipboard
 
 import (
-	"os/exec"
 	"regexp"
-	"strings"
+	"strconv"
 	"time"
-
-	"github.com/atotto/clipboard"
 )
 
 var (
@@ -22,7 +19,7 @@ func Monitor(suggestions chan<- string) {
 	oldText := ""
 
 	for {
-		currentText, err := clipboard.ReadAll()
+		currentText, err := Read()
 		if err == nil && currentText != oldText {
 			oldText = currentText
 			suggestion := generateSuggestion(currentText)
@@ -88,18 +85,5 @@ func getWgetCommand(url string) string {
 
 func generateFilename() string {
 	now := time.Now().UnixNano()
-	return "goterm_" + time.Now().Format("20060102_150405") + "_" + string(now%1000)
-}
-
-// WriteText writes text to the system clipboard
-func WriteText(text string) error {
-	return clipboard.WriteAll(text)
-}
-
-// Write copies text to the system clipboard
-func Write(text string) error {
-	// For macOS
-	cmd := exec.Command("pbcopy")
-	cmd.Stdin = strings.NewReader(text)
-	return cmd.Run()
+	return "goterm_" + time.Now().Format("20060102_150405") + "_" + strconv.FormatInt(now%1000, 10)
 }
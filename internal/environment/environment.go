@@ -0,0 +1,413 @@
+package environment
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+	"github/0PrashantYadav0/GO-TERM/pkg/utils"
+)
+
+// dynamicTTL is how long directory and git details are reused
+const dynamicTTL = 10 * time.Second
+
+// probeTimeout bounds every external command the collector runs
+const probeTimeout = 700 * time.Millisecond
+
+// knownPackageManagers are detected by looking them up on PATH
+var knownPackageManagers = []string{
+	"apt", "dnf", "yum", "pacman", "zypper", "apk", "brew", "port", "nix", "snap", "flatpak",
+	"winget", "choco", "scoop",
+}
+
+// versionArgs lists the tools whose versions are worth reporting and how to ask for them
+var versionArgs = map[string][]string{
+	"git": {"--version"}, "go": {"version"}, "node": {"--version"}, "npm": {"--version"},
+	"python3": {"--version"}, "python": {"--version"}, "pip": {"--version"}, "docker": {"--version"},
+	"kubectl": {"version", "--client"}, "helm": {"version", "--short"}, "terraform": {"version"},
+	"cargo": {"--version"}, "rustc": {"--version"}, "java": {"-version"}, "gcc": {"--version"},
+	"make": {"--version"}, "cmake": {"--version"}, "ruby": {"--version"}, "php": {"--version"},
+	"aws": {"--version"}, "gcloud": {"--version"}, "az": {"--version"}, "ffmpeg": {"-version"},
+	"tar": {"--version"}, "sed": {"--version"}, "grep": {"--version"}, "find": {"--version"},
+	"rg": {"--version"}, "fd": {"--version"}, "jq": {"--version"}, "curl": {"--version"},
+}
+
+// defaultTools are always reported when installed
+var defaultTools = []string{"git"}
+
+// projectMarkers are files worth naming in the directory summary
+var projectMarkers = []string{
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "requirements.txt", "Gemfile",
+	"pom.xml", "build.gradle", "Makefile", "CMakeLists.txt", "Dockerfile", "docker-compose.yml",
+	"compose.yaml", ".git", ".env", "README.md",
+}
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// dynamicInfo holds details that change as the user moves around
+type dynamicInfo struct {
+	dir       string
+	directory string
+	git       string
+	expires   time.Time
+}
+
+// Collector gathers details about the user's environment for AI prompts
+type Collector struct {
+	mu       sync.Mutex
+	static   map[string]string
+	versions map[string]string
+	dynamic  *dynamicInfo
+	history  func(n int) []string
+}
+
+// Default is the collector shared by all AI commands
+var Default = NewCollector()
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		versions: make(map[string]string),
+	}
+}
+
+// SetHistorySource sets the function used to fetch the last n commands
+func (c *Collector) SetHistorySource(source func(n int) []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = source
+}
+
+// History returns up to n recent commands, oldest first, or nothing when
+// history context is turned off
+func (c *Collector) History(n int) []string {
+	cfg := config.GetConfig().Context
+	if !cfg.Enabled || !cfg.History {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.history == nil {
+		return nil
+	}
+	return c.history(n)
+}
+
+// Collect returns a size-budgeted description of the environment. Tools
+// mentioned in hint have their versions included.
+func (c *Collector) Collect(hint string) string {
+	cfg := config.GetConfig().Context
+	if !cfg.Enabled {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.static == nil {
+		c.static = collectStatic()
+	}
+
+	// Fields in priority order; lower ones are dropped first when over budget
+	var fields []string
+	if cfg.OS {
+		fields = append(fields, "- os: "+c.static["os"])
+	}
+	if cfg.Arch {
+		fields = append(fields, "- arch: "+runtime.GOARCH)
+	}
+	if cfg.Shell {
+		fields = append(fields, "- shell: "+utils.GetShellName())
+	}
+	if cfg.PackageManagers && c.static["package_managers"] != "" {
+		fields = append(fields, "- package managers: "+c.static["package_managers"])
+	}
+	if cfg.ToolVersions {
+		if tools := c.toolVersions(hint); tools != "" {
+			fields = append(fields, "- tools: "+tools)
+		}
+	}
+	if cfg.Directory || cfg.Git {
+		dynamic := c.currentDynamic()
+		if cfg.Directory {
+			fields = append(fields, "- cwd: "+dynamic.directory)
+		}
+		if cfg.Git && dynamic.git != "" {
+			fields = append(fields, "- git: "+dynamic.git)
+		}
+	}
+
+	var recent []string
+	if cfg.History && c.history != nil && cfg.HistoryEntries > 0 {
+		recent = c.history(cfg.HistoryEntries)
+	}
+
+	return budget(fields, recent, cfg.Budget)
+}
+
+// Setting returns the parts of the environment that decide what a command
+// should look like and rarely change: the OS, the shell and the project
+// files in the working directory. Unlike Collect it leaves out history, git
+// state and file counts, so cached answers can be keyed on it.
+func (c *Collector) Setting() string {
+	cfg := config.GetConfig().Context
+	if !cfg.Enabled {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.static == nil {
+		c.static = collectStatic()
+	}
+
+	var parts []string
+	if cfg.OS {
+		parts = append(parts, c.static["os"])
+	}
+	if cfg.Shell {
+		parts = append(parts, utils.GetShellName())
+	}
+	if cfg.Directory {
+		dir, _ := os.Getwd()
+		names := map[string]bool{}
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				names[entry.Name()] = true
+			}
+		}
+		parts = append(parts, strings.Join(presentMarkers(names), ", "))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// budget joins fields into a block no longer than limit characters
+func budget(fields []string, recent []string, limit int) string {
+	var b strings.Builder
+	b.WriteString("Environment:\n")
+
+	for _, field := range fields {
+		if limit > 0 && b.Len()+len(field)+1 > limit {
+			continue
+		}
+		b.WriteString(field + "\n")
+	}
+
+	// Keep the newest history entries that still fit
+	header := "- recent commands:\n"
+	var kept []string
+	used := b.Len() + len(header)
+	for i := len(recent) - 1; i >= 0; i-- {
+		entry := "  " + recent[i] + "\n"
+		if limit > 0 && used+len(entry) > limit {
+			break
+		}
+		used += len(entry)
+		kept = append([]string{entry}, kept...)
+	}
+	if len(kept) > 0 {
+		b.WriteString(header)
+		for _, entry := range kept {
+			b.WriteString(entry)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// collectStatic gathers details that don't change during a session
+func collectStatic() map[string]string {
+	info := map[string]string{
+		"os": describeOS(),
+	}
+
+	var managers []string
+	for _, name := range knownPackageManagers {
+		if _, err := exec.LookPath(name); err == nil {
+			managers = append(managers, name)
+		}
+	}
+	info["package_managers"] = strings.Join(managers, ", ")
+
+	return info
+}
+
+// describeOS returns the distribution name and version with the platform
+func describeOS() string {
+	switch runtime.GOOS {
+	case "linux":
+		if name := readOSRelease(); name != "" {
+			return name + " (linux)"
+		}
+	case "darwin":
+		if version := probe("sw_vers", "-productVersion"); version != "" {
+			return "macOS " + version + " (darwin)"
+		}
+	}
+	return runtime.GOOS
+}
+
+// readOSRelease reads PRETTY_NAME from /etc/os-release
+func readOSRelease() string {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// toolVersions reports versions of the default tools and any known tool named in hint
+func (c *Collector) toolVersions(hint string) string {
+	tools := append([]string{}, defaultTools...)
+	for _, word := range strings.FieldsFunc(hint, func(r rune) bool {
+		return !(r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		word = strings.ToLower(word)
+		if _, known := versionArgs[word]; known {
+			tools = append(tools, word)
+		}
+	}
+
+	seen := map[string]bool{}
+	var parts []string
+	for _, tool := range tools {
+		if seen[tool] {
+			continue
+		}
+		seen[tool] = true
+
+		version, cached := c.versions[tool]
+		if !cached {
+			version = toolVersion(tool)
+			c.versions[tool] = version
+		}
+		if version != "" {
+			parts = append(parts, tool+" "+version)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// toolVersion returns the version number reported by a tool, if installed
+func toolVersion(tool string) string {
+	if _, err := exec.LookPath(tool); err != nil {
+		return ""
+	}
+	return versionPattern.FindString(probe(tool, versionArgs[tool]...))
+}
+
+// currentDynamic returns directory and git details, refreshing them when stale
+func (c *Collector) currentDynamic() *dynamicInfo {
+	dir, _ := os.Getwd()
+	if c.dynamic != nil && c.dynamic.dir == dir && time.Now().Before(c.dynamic.expires) {
+		return c.dynamic
+	}
+
+	c.dynamic = &dynamicInfo{
+		dir:       dir,
+		directory: describeDirectory(dir),
+		git:       describeGit(),
+		expires:   time.Now().Add(dynamicTTL),
+	}
+	return c.dynamic
+}
+
+// presentMarkers returns the project markers among names, sorted
+func presentMarkers(names map[string]bool) []string {
+	var markers []string
+	for _, marker := range projectMarkers {
+		if names[marker] {
+			markers = append(markers, marker)
+		}
+	}
+	sort.Strings(markers)
+	return markers
+}
+
+// describeDirectory summarizes the contents of dir
+func describeDirectory(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return dir
+	}
+
+	files, dirs := 0, 0
+	names := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs++
+		} else {
+			files++
+		}
+		names[entry.Name()] = true
+	}
+
+	markers := presentMarkers(names)
+	summary := fmt.Sprintf("%s (%d files, %d dirs", displayPath(dir), files, dirs)
+	if len(markers) > 0 {
+		summary += "; " + strings.Join(markers, ", ")
+	}
+	return summary + ")"
+}
+
+// describeGit returns the branch and a count of pending changes
+func describeGit() string {
+	branch := probe("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if branch == "" {
+		return ""
+	}
+
+	modified, untracked := 0, 0
+	for _, line := range strings.Split(probe("git", "status", "--porcelain"), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "??"):
+			untracked++
+		default:
+			modified++
+		}
+	}
+
+	return fmt.Sprintf("branch %s, %d modified, %d untracked", branch, modified, untracked)
+}
+
+// displayPath replaces the home directory with ~
+func displayPath(dir string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(dir, home) {
+		return "~" + filepath.ToSlash(dir[len(home):])
+	}
+	return dir
+}
+
+// probe runs a command with a short timeout and returns its trimmed output
+func probe(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
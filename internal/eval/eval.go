@@ -0,0 +1,213 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a file of cases for the AI commands
+type Suite struct {
+	Path    string `yaml:"-"`
+	Command string `yaml:"command"` // the default for cases that don't name one
+	Cases   []Case `yaml:"cases"`
+}
+
+// Case is one input for an AI command and what the answer must and must
+// not contain. For commands that suggest a command, such as hp, the
+// patterns are matched against the suggested command; for the others,
+// against the whole answer.
+type Case struct {
+	Name    string   `yaml:"name"`
+	Command string   `yaml:"command"` // hp, hm, he, chat or a custom command
+	Input   string   `yaml:"input"`
+	Failed  *Failure `yaml:"failed"` // the failed command hm is asked to fix
+	Expect  string   `yaml:"expect"` // a regex the answer must match
+	Forbid  []string `yaml:"forbid"` // regexes the answer must not match
+
+	expect *regexp.Regexp
+	forbid []*regexp.Regexp
+}
+
+// Failure describes the failed command in an hm case
+type Failure struct {
+	Command  string `yaml:"command"`
+	Stderr   string `yaml:"stderr"`
+	ExitCode int    `yaml:"exit_code"`
+}
+
+// Result is the outcome of one case
+type Result struct {
+	Suite    string
+	Case     Case
+	Answer   string
+	Problems []string // why the answer failed its patterns
+	Err      error    // the command itself failed
+	Duration time.Duration
+}
+
+// Passed reports whether the answer met every pattern
+func (r Result) Passed() bool {
+	return r.Err == nil && len(r.Problems) == 0
+}
+
+// Reproducible keeps prompts from depending on the machine or session, so
+// fixtures recorded elsewhere still match: environment context and man
+// pages are left out, and built-in templates ignore local edits
+func Reproducible() {
+	cfg := config.GetConfig()
+	cfg.Context.Enabled = false
+	cfg.ManPages.Enabled = false
+	prompts.IgnoreEdits()
+}
+
+// Load reads a suite and checks its cases
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	suite := &Suite{Path: path}
+	if err := yaml.Unmarshal(data, suite); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("%s: no cases", path)
+	}
+	if suite.Command == "" {
+		suite.Command = "hp"
+	}
+
+	for i := range suite.Cases {
+		c := &suite.Cases[i]
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("case %d", i+1)
+		}
+		if c.Command == "" {
+			c.Command = suite.Command
+		}
+		if err := c.compile(); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, c.Name, err)
+		}
+	}
+
+	return suite, nil
+}
+
+// compile checks a case and compiles its patterns
+func (c *Case) compile() error {
+	switch {
+	case c.Command == "hm" && c.Failed == nil:
+		return errors.New("hm cases need a failed command")
+	case c.Command != "hm" && c.Input == "":
+		return errors.New("input is required")
+	case c.Expect == "" && len(c.Forbid) == 0:
+		return errors.New("give an expect or forbid pattern")
+	}
+
+	var err error
+	if c.Expect != "" {
+		if c.expect, err = regexp.Compile(c.Expect); err != nil {
+			return fmt.Errorf("expect: %w", err)
+		}
+	}
+	for _, pattern := range c.Forbid {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("forbid: %w", err)
+		}
+		c.forbid = append(c.forbid, re)
+	}
+	return nil
+}
+
+// Run runs the cases of a suite whose names match filter, calling done
+// after each one
+func Run(ctx context.Context, suite *Suite, filter *regexp.Regexp, done func(Result)) []Result {
+	var results []Result
+	for _, c := range suite.Cases {
+		if filter != nil && !filter.MatchString(c.Name) {
+			continue
+		}
+
+		start := time.Now()
+		result := Result{Suite: suite.Path, Case: c}
+		result.Answer, result.Err = answer(ctx, c)
+		result.Duration = time.Since(start)
+		if result.Err == nil {
+			result.Problems = c.check(result.Answer)
+		}
+
+		results = append(results, result)
+		if done != nil {
+			done(result)
+		}
+	}
+	return results
+}
+
+// check lists how an answer fails the case's patterns
+func (c Case) check(answer string) []string {
+	var problems []string
+	if c.expect != nil && !c.expect.MatchString(answer) {
+		problems = append(problems, "does not match "+c.Expect)
+	}
+	for _, re := range c.forbid {
+		if match := re.FindString(answer); match != "" {
+			problems = append(problems, fmt.Sprintf("contains forbidden %q", match))
+		}
+	}
+	return problems
+}
+
+// answer asks the case's command for an answer
+func answer(ctx context.Context, c Case) (string, error) {
+	switch c.Command {
+	case "hp":
+		suggestion, err := ai.GenerateCommandForHp(ctx, c.Input)
+		if err != nil {
+			return "", err
+		}
+		return suggestion.Command, nil
+
+	case "hm":
+		failed := &ai.CommandLog{}
+		failed.Command.Raw = c.Failed.Command
+		failed.Output.Stderr = c.Failed.Stderr
+		failed.Output.ExitCode = c.Failed.ExitCode
+		suggestion, err := ai.GenerateFix(ctx, failed)
+		if err != nil {
+			return "", err
+		}
+		return suggestion.Command, nil
+
+	case "he":
+		return ai.ExplainCommand(ctx, c.Input, nil)
+
+	case "chat":
+		return ai.ChatWithAI(ctx, c.Input, nil)
+	}
+
+	command, ok := config.GetConfig().Prompts.Commands[c.Command]
+	if !ok {
+		return "", fmt.Errorf("unknown command %q", c.Command)
+	}
+	if command.Output == ai.OutputCommand {
+		suggestion, err := ai.SuggestCustomCommand(ctx, c.Command, c.Input)
+		if err != nil {
+			return "", err
+		}
+		return suggestion.Command, nil
+	}
+	return ai.RunCustomCommand(ctx, c.Command, c.Input)
+}
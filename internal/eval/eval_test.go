@@ -0,0 +1,39 @@
+package eval
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github/0PrashantYadav0/GO-TERM/internal/ai"
+)
+
+// TestCommandsReplay runs eval/commands.yaml against the recorded fixtures,
+// offline. After a prompt change, record them again with
+// goterm eval --record eval/commands.yaml.
+func TestCommandsReplay(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the fixtures were recorded with platform linux in the prompts")
+	}
+
+	// A fresh home keeps the user's config, prompts and error log out of it
+	t.Setenv("HOME", t.TempDir())
+	Reproducible()
+	ai.UseFixtures("../../eval/fixtures", ai.FixturesReplay)
+
+	suite, err := Load("../../eval/commands.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ai.WithOptions(context.Background(), ai.RequestOptions{NoCache: true})
+	for _, result := range Run(ctx, suite, nil, nil) {
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.Case.Name, result.Err)
+			continue
+		}
+		for _, problem := range result.Problems {
+			t.Errorf("%s: %q %s", result.Case.Name, result.Answer, problem)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of script the linter understands
+const (
+	Bash   = "bash"
+	GoTerm = "goterm" // one GO-TERM command per line, run without a shell
+)
+
+// Rules a finding can come from
+const (
+	StrictMode  = "strict-mode"
+	UnquotedVar = "unquoted-variable"
+	UselessCat  = "useless-cat"
+	RiskyRm     = "risky-rm"
+	ShellSyntax = "shell-syntax"
+)
+
+// Finding is one problem in a script
+type Finding struct {
+	Line    int // 1-based; 0 for the script as a whole
+	Rule    string
+	Message string
+}
+
+// String formats a finding as "line 3: message (rule)"
+func (f Finding) String() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("%s (%s)", f.Message, f.Rule)
+	}
+	return fmt.Sprintf("line %d: %s (%s)", f.Line, f.Message, f.Rule)
+}
+
+// Check lints a script of the given kind, returning its findings in line
+// order
+func Check(script string, kind string) []Finding {
+	var findings []Finding
+	lines := strings.Split(script, "\n")
+
+	if kind == Bash && !hasStrictMode(lines) {
+		findings = append(findings, Finding{Rule: StrictMode, Message: "missing set -euo pipefail, so failed commands, unset variables and broken pipes go unnoticed"})
+	}
+
+	heredoc := ""
+	for i, line := range lines {
+		number := i + 1
+		if heredoc != "" {
+			if strings.TrimSpace(line) == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+		code := stripComment(line)
+		if word := heredocWord(code); word != "" {
+			heredoc = word
+		}
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+
+		if kind == GoTerm {
+			findings = append(findings, checkSimpleCommand(code, number)...)
+		} else {
+			findings = append(findings, checkQuoting(code, number)...)
+			if match := uselessCat.FindStringSubmatch(code); match != nil {
+				findings = append(findings, Finding{Line: number, Rule: UselessCat, Message: fmt.Sprintf("cat %s | …: give %s to the next command or use < %s", match[1], match[1], match[1])})
+			}
+		}
+		findings = append(findings, checkRm(code, number)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// hasStrictMode reports whether set turns on errexit, nounset and pipefail,
+// in one line or several
+func hasStrictMode(lines []string) bool {
+	var errexit, nounset, pipefail bool
+	for _, line := range lines {
+		fields := strings.Fields(stripComment(line))
+		if len(fields) == 0 || fields[0] != "set" {
+			continue
+		}
+		for i, field := range fields[1:] {
+			switch {
+			case field == "-o" && i+2 < len(fields):
+				switch fields[i+2] {
+				case "errexit":
+					errexit = true
+				case "nounset":
+					nounset = true
+				case "pipefail":
+					pipefail = true
+				}
+			case strings.HasPrefix(field, "-") && !strings.HasPrefix(field, "--"):
+				errexit = errexit || strings.Contains(field, "e")
+				nounset = nounset || strings.Contains(field, "u")
+				pipefail = pipefail || (strings.HasSuffix(field, "o") && i+2 < len(fields) && fields[i+2] == "pipefail")
+			}
+		}
+	}
+	return errexit && nounset && pipefail
+}
+
+// heredocStart matches the start of a here-document, capturing its end word
+var heredocStart = regexp.MustCompile(`(?:^|[^<])<<-?\s*['"]?([A-Za-z_]\w*)`)
+
+// heredocWord returns the end word of a here-document started in code. A
+// << inside quotes or arithmetic, where it is a shift, starts none.
+func heredocWord(code string) string {
+	unquoted := unquotedText(code)
+	for _, match := range heredocStart.FindAllStringSubmatchIndex(code, -1) {
+		at := match[0] + strings.Index(code[match[0]:], "<<")
+		before := code[:at]
+		if unquoted[at] != '<' || strings.Count(before, "((") > strings.Count(before, "))") {
+			continue
+		}
+		return code[match[2]:match[3]]
+	}
+	return ""
+}
+
+// uselessCat matches cat of a single file piped into another command
+var uselessCat = regexp.MustCompile(`(?:^|[;&|(]\s*|\$\(\s*)cat\s+("[^"]+"|'[^']+'|[^\s|;&<>()-][^\s|;&<>()]*)\s*\|[^|]`)
+
+// stripComment drops a # comment that isn't inside quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// safeSpecials are parameters that never hold spaces or globs
+const safeSpecials = "?#$!-0"
+
+// variableName matches the name after a $, or a ${...} expansion
+var variableName = regexp.MustCompile(`^(\{[^}]*\}|[A-Za-z_]\w*|[1-9@*])`)
+
+// checkQuoting finds variables expanded outside double quotes, where the
+// shell splits and globs their values. Assignments, [[ ]] tests, case
+// words and arithmetic are left alone, as none of them split.
+func checkQuoting(code string, number int) []Finding {
+	trimmed := strings.TrimSpace(code)
+	if strings.HasPrefix(trimmed, "case ") || strings.HasPrefix(trimmed, "((") {
+		return nil
+	}
+
+	var findings []Finding
+	reported := map[string]bool{}
+	var quote byte
+	inTest, wordStart, inAssignment := false, true, false
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+			continue
+		}
+
+		if wordStart && assignment.MatchString(code[i:]) {
+			inAssignment = true
+		}
+		wordStart = false
+
+		switch {
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ' ' || c == '\t' || c == ';' || c == '|' || c == '&' || c == '(':
+			wordStart, inAssignment = true, false
+		case strings.HasPrefix(code[i:], "[["):
+			inTest = true
+			i++
+		case strings.HasPrefix(code[i:], "]]"):
+			inTest = false
+			i++
+		case strings.HasPrefix(code[i:], "$(("):
+			if end := strings.Index(code[i:], "))"); end >= 0 {
+				i += end + 1
+			}
+		case c == '$' && i+1 < len(code):
+			if strings.IndexByte(safeSpecials, code[i+1]) >= 0 || inTest || inAssignment {
+				continue
+			}
+			name := variableName.FindString(code[i+1:])
+			if name == "" || strings.Contains(name, ":?") || strings.HasPrefix(name, "{#") {
+				continue
+			}
+			variable := "$" + name
+			if !reported[variable] {
+				reported[variable] = true
+				findings = append(findings, Finding{Line: number, Rule: UnquotedVar, Message: fmt.Sprintf(`%s is unquoted; write "%s" so spaces or globs in its value don't split it`, variable, variable)})
+			}
+			i += len(name)
+		}
+	}
+	return findings
+}
+
+// assignment matches a NAME= word, including after local, export and the like
+var assignment = regexp.MustCompile(`^[A-Za-z_]\w*(\[[^]]*\])?\+?=`)
+
+// recursiveFlag matches an rm flag that deletes directories
+var recursiveFlag = regexp.MustCompile(`^(-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)$`)
+
+// rmCommand finds rm at the start of a command
+var rmCommand = regexp.MustCompile(`(?:^|[;&|(]|\bsudo|\bthen|\bdo)\s*rm\s+([^;&|)]*)`)
+
+// dangerousTargets are paths rm -r must never be pointed at
+var dangerousTargets = map[string]bool{"/": true, "/*": true, "~": true, "~/": true, "~/*": true, "*": true, ".": true, "..": true, "$HOME": true}
+
+// checkRm flags rm -r of a bare variable, which deletes from / when the
+// variable is empty, and of root, home or wildcard paths
+func checkRm(code string, number int) []Finding {
+	var findings []Finding
+	for _, match := range rmCommand.FindAllStringSubmatch(code, -1) {
+		words := strings.Fields(match[1])
+		recursive := false
+		for _, word := range words {
+			if recursiveFlag.MatchString(word) {
+				recursive = true
+			}
+		}
+		if !recursive {
+			continue
+		}
+
+		for _, word := range words {
+			if strings.HasPrefix(word, "-") {
+				continue
+			}
+			target := strings.Trim(word, `"'`)
+			switch {
+			case dangerousTargets[target]:
+				findings = append(findings, Finding{Line: number, Rule: RiskyRm, Message: fmt.Sprintf("rm -r %s deletes a root, home or wildcard path", target)})
+			case strings.HasPrefix(target, "$") && !strings.Contains(target, ":?"):
+				name := variableName.FindString(target[1:])
+				name = strings.Trim(name, "{}")
+				findings = append(findings, Finding{Line: number, Rule: RiskyRm, Message: fmt.Sprintf("rm -r %s deletes from the wrong place if %s is empty; use ${%s:?}", word, name, name)})
+			}
+		}
+	}
+	return findings
+}
+
+// shellOnly are constructs that need a shell, with what they're called
+var shellOnly = []struct {
+	pattern *regexp.Regexp
+	name    string
+}{
+	{regexp.MustCompile(`\|\||&&`), "&& and ||"},
+	{regexp.MustCompile(`\|`), "pipes"},
+	{regexp.MustCompile(`[<>]`), "redirection"},
+	{regexp.MustCompile(`;`), "; between commands"},
+	{regexp.MustCompile("\\$\\(|`"), "command substitution"},
+	{regexp.MustCompile(`\$\{?[A-Za-z_]`), "variables"},
+	{regexp.MustCompile(`\*`), "globs"},
+	{regexp.MustCompile(`^\s*(if|then|else|fi|for|while|do|done|case|esac|function)\b|^\s*\w+\s*\(\)`), "shell control flow"},
+}
+
+// checkSimpleCommand flags shell syntax in a GO-TERM script line. GO-TERM
+// runs each line as a single program without a shell, so none of it works.
+func checkSimpleCommand(code string, number int) []Finding {
+	unquoted := unquotedText(code)
+
+	var findings []Finding
+	for _, s := range shellOnly {
+		if s.pattern.MatchString(unquoted) {
+			findings = append(findings, Finding{Line: number, Rule: ShellSyntax, Message: fmt.Sprintf("GO-TERM runs each line without a shell, so %s won't work; use bash -c '…' or a bash script", s.name)})
+			break
+		}
+	}
+	return findings
+}
+
+// unquotedText blanks out the quoted parts of a line, keeping its length
+func unquotedText(code string) string {
+	b := []byte(code)
+	var quote byte
+	for i := 0; i < len(b); i++ {
+		switch {
+		case quote != 0:
+			if b[i] == quote {
+				quote = 0
+			}
+			b[i] = ' '
+		case b[i] == '\\' && i+1 < len(b):
+			b[i], b[i+1] = ' ', ' '
+			i++
+		case b[i] == '\'' || b[i] == '"':
+			quote = b[i]
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStrictMode(t *testing.T) {
+	tests := []struct {
+		script string
+		strict bool
+	}{
+		{"set -euo pipefail", true},
+		{"set -e -u -o pipefail", true},
+		{"set -e\nset -u\nset -o pipefail", true},
+		{"set -o errexit -o nounset -o pipefail", true},
+		{"set -euxo pipefail", true},
+		{"set -eu -o pipefail # strict", true},
+		{"set -eu", false},
+		{"set -e -o pipefail", false},
+		{"set -euo", false},
+		{"echo 'set -euo pipefail'", false},
+		{"# set -euo pipefail", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasStrictMode(strings.Split(tt.script, "\n")); got != tt.strict {
+			t.Errorf("hasStrictMode(%q) = %v, want %v", tt.script, got, tt.strict)
+		}
+	}
+}
+
+func TestCheckBash(t *testing.T) {
+	tests := []struct {
+		line  string
+		rules []string
+	}{
+		{`echo "$x"`, nil},
+		{`echo $x`, []string{UnquotedVar}},
+		{`echo $x $x $y`, []string{UnquotedVar, UnquotedVar}},
+		{`local x=$1`, nil},
+		{`export PATH=$HOME/bin:$PATH`, nil},
+		{`[[ $x ]]`, nil},
+		{`[[ -n $x && $y == z ]] && echo ok`, nil},
+		{`echo $((i+1))`, nil},
+		{`(( count += $n ))`, nil},
+		{`echo $? $# $$`, nil},
+		{`echo ${#items[@]}`, nil},
+		{`echo '$x'`, nil},
+		{`echo \$x`, nil},
+		{`echo $x # and $y`, []string{UnquotedVar}},
+		{`case $1 in`, nil},
+		{`cat file.txt | grep x`, []string{UselessCat}},
+		{`cat a b | grep x`, nil},
+		{`cat -n file | grep x`, nil},
+		{`rm -rf "${DIR:?}"/x`, nil},
+		{`rm -rf "$DIR"`, []string{RiskyRm}},
+		{`rm -rf $DIR/cache`, []string{UnquotedVar, RiskyRm}},
+		{`rm -r /`, []string{RiskyRm}},
+		{`sudo rm -rf ~`, []string{RiskyRm}},
+		{`rm -f "$file"`, nil},
+		{`echo rm -rf /`, nil},
+		{`if true; then rm -rf *; fi`, []string{RiskyRm}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, f := range Check("set -euo pipefail\n"+tt.line, Bash) {
+			got = append(got, f.Rule)
+		}
+		if !slices.Equal(got, tt.rules) {
+			t.Errorf("Check(%q) = %q, want %q", tt.line, got, tt.rules)
+		}
+	}
+}
+
+func TestHeredoc(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		lines  []int // lines with findings
+	}{
+		{"body skipped", "cat <<EOF\n$x\nrm -rf /\nEOF\necho $y", []int{5}},
+		{"quoted end word", "cat <<'EOF'\n$x\nEOF\necho $y", []int{4}},
+		{"indented end word", "if true; then\n\tcat <<-EOF\n\t$x\n\tEOF\nfi\necho $y", []int{6}},
+		{"here-string", "cat <<< \"$x\"\necho $y", []int{2}},
+		{"shift in arithmetic", "echo $((1 << n))\necho $((x<<y))\necho $y", []int{3}},
+		{"in a comment", "# cat <<EOF\necho $y", []int{2}},
+		{"in quotes", "echo \"<<EOF\"\necho $y", []int{2}},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, f := range Check("set -euo pipefail\n"+tt.script, Bash) {
+			got = append(got, f.Line-1)
+		}
+		if !slices.Equal(got, tt.lines) {
+			t.Errorf("%s: findings on lines %v, want %v", tt.name, got, tt.lines)
+		}
+	}
+}
+
+func TestCheckGoTerm(t *testing.T) {
+	tests := []struct {
+		line   string
+		syntax bool
+	}{
+		{"git status", false},
+		{"git commit -m 'fix: a | b'", false},
+		{"ls | wc -l", true},
+		{"make && make test", true},
+		{"echo $HOME", true},
+		{"ls *.go", true},
+		{"for f in a b; do echo $f; done", true},
+	}
+
+	for _, tt := range tests {
+		findings := Check(tt.line, GoTerm)
+		if got := len(findings) > 0 && findings[0].Rule == ShellSyntax; got != tt.syntax {
+			t.Errorf("Check(%q, GoTerm) = %v, want shell syntax %v", tt.line, findings, tt.syntax)
+		}
+	}
+}
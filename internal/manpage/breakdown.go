@@ -0,0 +1,227 @@
+package manpage
+
+import (
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github/0PrashantYadav0/GO-TERM/internal/terminal"
+)
+
+// operators describes shell control operators
+var operators = map[string]string{
+	"|":  "pipe: the output of the command on the left becomes the input of the one on the right",
+	"||": "run the next command only if the previous one fails",
+	"&&": "run the next command only if the previous one succeeds",
+	";":  "run the next command after the previous one, whatever its result",
+	"&":  "run the command before it in the background",
+}
+
+// redirections describes shell redirections
+var redirections = map[string]string{
+	">":    "write standard output to the file, replacing its contents",
+	">>":   "append standard output to the file",
+	"1>":   "write standard output to the file, replacing its contents",
+	"1>>":  "append standard output to the file",
+	"2>":   "write error output to the file, replacing its contents",
+	"2>>":  "append error output to the file",
+	"2>&1": "send error output to the same place as standard output",
+	"1>&2": "send standard output to the same place as error output",
+	">&2":  "send standard output to the same place as error output",
+	"&>":   "write both standard and error output to the file",
+	"&>>":  "append both standard and error output to the file",
+	"<":    "read standard input from the file",
+	"<<":   "here-document: read standard input from the following lines",
+}
+
+// valuePlaceholder matches the metavariable of a flag that takes a value,
+// as in "--file=ARCHIVE", "--lines=[-]NUM", "-n NUM" or "-o <file>".
+// Optional values such as "--color[=WHEN]" must be attached, so they
+// don't count.
+var valuePlaceholder = regexp.MustCompile(`(?:=|\s)(?:\[-\])?([A-Z][A-Z0-9_-]*|<[^>]+>)`)
+
+// Annotation explains one token of a command line
+type Annotation struct {
+	Token  terminal.Token
+	Text   string
+	Source string // the page it came from, e.g. "man tar"; empty for shell syntax
+}
+
+// doc is a fetched documentation page split into lines
+type doc struct {
+	page  Page
+	lines []string
+}
+
+// LooksLikeCommand reports whether text is a command line rather than a
+// question about one
+func LooksLikeCommand(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	first := filepath.Base(fields[0])
+	if !isCommandName(first) {
+		return false
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return false
+	}
+	if !commonWords[first] {
+		return true
+	}
+
+	// "find" or "watch" alone may be English; with shell syntax they aren't
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.ContainsAny(field, "|><;&") {
+			return true
+		}
+	}
+	return false
+}
+
+// Breakdown annotates every token of a command line from the local man
+// pages and --help output, without the AI
+func Breakdown(line string) []Annotation {
+	docs := map[string]*doc{}
+	load := func(name string, manOnly bool) *doc {
+		if d, ok := docs[name]; ok {
+			return d
+		}
+		text, source := "", ""
+		if manOnly {
+			text, source = fetchMan(name)
+		} else {
+			text, source = fetch(name)
+		}
+		var d *doc
+		if text != "" {
+			lines := strings.Split(text, "\n")
+			d = &doc{page: Page{Command: name, Source: source, Synopsis: synopsis(lines, source)}, lines: lines}
+		}
+		docs[name] = d
+		return d
+	}
+
+	var (
+		annotations []Annotation
+		command     string
+		current     *doc
+		args        int
+		pending     string // a flag waiting for its value
+		target      string // a redirection waiting for its file
+	)
+
+	for _, token := range terminal.NewHighlighter().Tokenize(line) {
+		a := Annotation{Token: token}
+
+		switch token.Type {
+		case terminal.Command:
+			command = filepath.Base(token.Value)
+			current = load(command, false)
+			args, pending = 0, ""
+			a.Text = command + ": (no documentation found)"
+			if current != nil {
+				a.Text, a.Source = command+": "+current.page.Synopsis, current.page.Cite()
+			}
+
+		case terminal.Flag:
+			a.Text, a.Source, pending = describeFlag(token.Value, command, current)
+
+		case terminal.Pipe:
+			a.Text = operators[token.Value]
+			command, current = "", nil
+
+		case terminal.Redirection:
+			a.Text = redirections[token.Value]
+			if a.Text == "" {
+				a.Text = "redirection"
+			}
+			// Duplications such as 2>&1 name no file
+			if !strings.Contains(token.Value, "&") || strings.HasPrefix(token.Value, "&") {
+				target = token.Value
+			}
+
+		case terminal.Comment:
+			a.Text = "comment, ignored by the shell"
+
+		default:
+			switch {
+			case target == "<<":
+				a.Text, target = "marker that ends the here-document", ""
+			case target != "":
+				a.Text, target = "file for "+target, ""
+			case command == "":
+				a.Text = "variable assignment for the command that follows"
+			case pending != "":
+				a.Text, a.Source = "value for "+pending, current.page.Cite()
+				pending = ""
+				args++
+			case args == 0 && current != nil && isCommandName(token.Value) && load(command+"-"+token.Value, true) != nil:
+				// A subcommand with its own page, such as git-commit
+				current = load(command+"-"+token.Value, true)
+				a.Text, a.Source = "subcommand: "+current.page.Synopsis, current.page.Cite()
+				args++
+			case token.Type == terminal.Variable:
+				a.Text = "expands to the value of " + token.Value
+				args++
+			default:
+				a.Text = "argument to " + command
+				args++
+			}
+		}
+
+		annotations = append(annotations, a)
+	}
+
+	return annotations
+}
+
+// describeFlag looks a flag up in the current page. It also returns the
+// flag's value placeholder when the flag takes one.
+func describeFlag(flag, command string, current *doc) (string, string, string) {
+	if current == nil {
+		return "flag of " + command, "", ""
+	}
+
+	var found []string
+	pending := ""
+	candidates := flagCandidates(flag)
+	for _, candidate := range candidates {
+		section, ok := findFlag(current.lines, candidate)
+		if !ok {
+			continue
+		}
+		found = append(found, collapse(section))
+		if value := takesValue(section); value != "" && !strings.Contains(flag, "=") {
+			pending = candidate + " (" + value + ")"
+		}
+		if candidate == candidates[0] {
+			break
+		}
+	}
+
+	if len(found) == 0 {
+		return "flag of " + command + " (not in its documentation)", "", ""
+	}
+	return strings.Join(found, "; "), current.page.Cite(), pending
+}
+
+// takesValue returns the placeholder name when a flag's documentation shows
+// it taking a value, as in "-f, --file=ARCHIVE" or "-n NUM"
+func takesValue(section string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(section), "\n")
+	// In --help output the description follows the names after a wide gap
+	names, _, _ := strings.Cut(first, "  ")
+	if match := valuePlaceholder.FindStringSubmatch(names); match != nil {
+		return strings.Trim(match[1], "<>")
+	}
+	return ""
+}
+
+// collapse joins a section onto one line
+func collapse(text string) string {
+	return strings.Join(strings.Fields(strings.TrimSuffix(text, "…")), " ")
+}
@@ -0,0 +1,415 @@
+package manpage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// lookupTimeout bounds each man or --help call
+const lookupTimeout = 3 * time.Second
+
+// maxSectionLines caps the text kept for a single flag
+const maxSectionLines = 12
+
+// Sources a page can come from
+const (
+	SourceMan  = "man"
+	SourceHelp = "--help"
+)
+
+// commonWords are installed commands that are more often plain English in
+// a question; they only count as commands when followed by a flag
+var commonWords = map[string]bool{
+	"which": true, "what": true, "time": true, "test": true, "file": true, "yes": true,
+	"true": true, "false": true, "who": true, "more": true, "help": true, "look": true,
+	"last": true, "type": true, "do": true, "w": true, "write": true, "users": true,
+	"free": true, "print": true, "join": true, "split": true, "watch": true, "wall": true,
+	"top": true, "make": true, "find": true, "sort": true, "kill": true, "date": true,
+	"install": true, "link": true, "touch": true, "tail": true, "head": true, "cut": true,
+	"paste": true, "expand": true, "env": true, "less": true, "see": true, "info": true,
+}
+
+// neverRun are commands not worth the risk of running with --help, in case
+// a build ignores the flag
+var neverRun = map[string]bool{
+	"shutdown": true, "reboot": true, "halt": true, "poweroff": true, "init": true, "telinit": true,
+	"rm": true, "dd": true, "mkfs": true, "kill": true, "killall": true, "pkill": true,
+}
+
+// systemDirs are where package managers install binaries; only these are
+// run with --help, so a local script that ignores the flag never runs
+var systemDirs = map[string]bool{
+	"/bin": true, "/sbin": true, "/usr/bin": true, "/usr/sbin": true,
+	"/usr/local/bin": true, "/usr/local/sbin": true,
+	"/opt/homebrew/bin": true, "/opt/homebrew/sbin": true,
+}
+
+// overstrike matches the backspace sequences man uses for bold and underline
+var overstrike = regexp.MustCompile(".\x08")
+
+// sectionHeader matches an unindented man page heading such as "OPTIONS"
+var sectionHeader = regexp.MustCompile(`^[A-Z][A-Z ]+$`)
+
+// Section is the documentation of one flag
+type Section struct {
+	Flag string
+	Text string
+}
+
+// Page is what was found for one command
+type Page struct {
+	Command  string
+	Source   string
+	Synopsis string
+	Sections []Section
+}
+
+// Cite returns how the model should refer to this page, e.g. "man tar"
+func (p Page) Cite() string {
+	if p.Source == SourceMan {
+		return "man " + p.Command
+	}
+	return p.Command + " --help"
+}
+
+// usage is a command named in a query and the flags used with it
+type usage struct {
+	command    string
+	subcommand string
+	flags      []string
+}
+
+// Lookup finds the commands named in a query and extracts the parts of
+// their local documentation that cover the flags used, keeping the total
+// under budget bytes
+func Lookup(query string, budget int) []Page {
+	var pages []Page
+	used := 0
+
+	for _, u := range commandsIn(query) {
+		// Tools like git document subcommands in pages such as git-commit
+		name := u.command
+		var text, source string
+		if u.subcommand != "" {
+			name = u.command + "-" + u.subcommand
+			text, source = fetchMan(name)
+		}
+		if text == "" {
+			name = u.command
+			text, source = fetch(name)
+		}
+		if text == "" {
+			continue
+		}
+
+		page := extract(name, source, text, u.flags)
+		size := len(page.Format())
+		if used+size > budget {
+			// Keep the synopsis when the flag sections don't fit
+			page.Sections = nil
+			size = len(page.Format())
+			if used+size > budget {
+				break
+			}
+		}
+		used += size
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// commandsIn finds installed commands in a query along with their flags
+func commandsIn(query string) []usage {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '`' || r == '"' || r == '\'' || r == '|' || r == ';' || r == '&' || r == '(' || r == ')'
+	})
+
+	var found []usage
+	seen := map[string]bool{}
+	for i, word := range words {
+		word = strings.TrimRight(word, ",.?!:")
+		if word == "" || strings.HasPrefix(word, "-") || seen[word] || !isCommandName(word) {
+			continue
+		}
+
+		var flags []string
+		subcommand := ""
+		for j, next := range words[i+1:] {
+			next = strings.TrimRight(next, ",.?!:")
+			if !strings.HasPrefix(next, "-") {
+				// Arguments may sit between flags, but another command ends the list
+				if installed(next) {
+					break
+				}
+				if j == 0 && isCommandName(next) {
+					subcommand = next
+				}
+				continue
+			}
+			flags = append(flags, next)
+		}
+
+		if commonWords[word] && len(flags) == 0 && i != 0 {
+			continue
+		}
+		if _, err := exec.LookPath(word); err != nil {
+			continue
+		}
+
+		seen[word] = true
+		found = append(found, usage{command: word, subcommand: subcommand, flags: flags})
+	}
+
+	return found
+}
+
+// isCommandName reports whether a word looks like an executable name
+func isCommandName(word string) bool {
+	if word == "" || len(word) > 40 {
+		return false
+	}
+	for _, r := range word {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == '+') {
+			return false
+		}
+	}
+	return word[0] >= 'a' && word[0] <= 'z'
+}
+
+// installed reports whether a word names a command on PATH that is not
+// usually plain English
+func installed(word string) bool {
+	if !isCommandName(word) || commonWords[word] {
+		return false
+	}
+	_, err := exec.LookPath(word)
+	return err == nil
+}
+
+// fetch returns the man page of a command, or its --help output when there
+// is no man page and the command is a system binary
+func fetch(command string) (string, string) {
+	if text, source := fetchMan(command); text != "" {
+		return text, source
+	}
+	if neverRun[command] {
+		return "", ""
+	}
+	path, ok := systemBinary(command)
+	if !ok {
+		return "", ""
+	}
+	if text := run(path, "--help"); text != "" {
+		return text, SourceHelp
+	}
+	return "", ""
+}
+
+// systemBinary returns the path a command resolves to when that is in one
+// of the systemDirs
+func systemBinary(command string) (string, bool) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil || !systemDirs[filepath.Dir(path)] {
+		return "", false
+	}
+	return path, true
+}
+
+// fetchMan returns a man page as plain text
+func fetchMan(name string) (string, string) {
+	if text := run("man", "-P", "cat", name); text != "" {
+		return overstrike.ReplaceAllString(text, ""), SourceMan
+	}
+	return "", ""
+}
+
+// run executes a documentation command with a timeout, returning "" on
+// failure
+func run(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "MANWIDTH=80", "MAN_KEEP_FORMATTING=", "PAGER=cat", "LC_ALL=C")
+	out, err := cmd.CombinedOutput()
+
+	// Many tools exit non-zero after printing --help, so keep any output
+	// that isn't just an error
+	text := strings.TrimSpace(string(out))
+	if err != nil && (len(text) < 80 || ctx.Err() != nil) {
+		return ""
+	}
+	return text
+}
+
+// extract pulls the synopsis and the sections for each flag out of a page
+func extract(command, source, text string, flags []string) Page {
+	lines := strings.Split(text, "\n")
+	page := Page{Command: command, Source: source, Synopsis: synopsis(lines, source)}
+
+	seen := map[string]bool{}
+	for _, flag := range flags {
+		candidates := flagCandidates(flag)
+		for _, candidate := range candidates {
+			if seen[candidate] {
+				continue
+			}
+			if section, ok := findFlag(lines, candidate); ok {
+				seen[candidate] = true
+				page.Sections = append(page.Sections, Section{Flag: candidate, Text: section})
+				if candidate == candidates[0] {
+					break
+				}
+			}
+		}
+	}
+
+	return page
+}
+
+// flagCandidates expands a flag as written into the names to look up:
+// "--file=x" becomes "--file", and bundled short flags such as "-xzf"
+// become "-x", "-z" and "-f" unless the page documents "-xzf" itself
+func flagCandidates(flag string) []string {
+	flag, _, _ = strings.Cut(flag, "=")
+	if strings.HasPrefix(flag, "--") || len(flag) <= 2 {
+		return []string{flag}
+	}
+
+	candidates := []string{flag}
+	for _, r := range flag[1:] {
+		candidates = append(candidates, "-"+string(r))
+	}
+	return candidates
+}
+
+// findFlag returns the indented paragraph documenting a flag
+func findFlag(lines []string, flag string) (string, bool) {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !startsWithFlag(trimmed, flag) {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		section := []string{trimmed}
+		for _, next := range lines[i+1:] {
+			nextTrimmed := strings.TrimSpace(next)
+			nextIndent := len(next) - len(strings.TrimLeft(next, " \t"))
+			if nextTrimmed == "" {
+				if len(section) > 1 {
+					break
+				}
+				continue
+			}
+			// The next option or heading ends the section
+			if nextIndent <= indent || strings.HasPrefix(nextTrimmed, "-") {
+				break
+			}
+			section = append(section, nextTrimmed)
+			if len(section) == maxSectionLines {
+				section = append(section, "…")
+				break
+			}
+		}
+		return strings.Join(section, "\n"), true
+	}
+	return "", false
+}
+
+// startsWithFlag reports whether an option line documents flag, as in
+// "-x, --extract" or "--lines=NUM"
+func startsWithFlag(line, flag string) bool {
+	for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if !strings.HasPrefix(field, "-") {
+			return false
+		}
+		name, _, _ := strings.Cut(field, "=")
+		name, _, _ = strings.Cut(name, "[")
+		if name == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// synopsis returns the one-line description of a command: the NAME
+// section of a man page or the first line of --help
+func synopsis(lines []string, source string) string {
+	if source == SourceHelp {
+		// Prefer the description that usually follows the usage lines
+		usage := ""
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			lower := strings.ToLower(line)
+			switch {
+			case line == "":
+			case strings.HasPrefix(lower, "usage:") || strings.HasPrefix(lower, "or:"):
+				if usage == "" {
+					usage = line
+				}
+			case strings.HasPrefix(line, "-"):
+				return usage
+			default:
+				return line
+			}
+		}
+		return usage
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "NAME" {
+			continue
+		}
+		var name []string
+		for _, next := range lines[i+1:] {
+			if sectionHeader.MatchString(next) {
+				break
+			}
+			if next = strings.TrimSpace(next); next != "" {
+				name = append(name, next)
+			}
+		}
+		return strings.Join(name, " ")
+	}
+	return ""
+}
+
+// Format renders a page for a prompt or the terminal
+func (p Page) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]", p.Cite())
+	if p.Synopsis != "" {
+		b.WriteString(" " + p.Synopsis)
+	}
+	b.WriteString("\n")
+	for _, section := range p.Sections {
+		b.WriteString(indent(section.Text) + "\n")
+	}
+	return b.String()
+}
+
+// FormatAll renders several pages separated by blank lines
+func FormatAll(pages []Page) string {
+	parts := make([]string, len(pages))
+	for i, page := range pages {
+		parts[i] = page.Format()
+	}
+	return strings.TrimRight(strings.Join(parts, "\n"), "\n")
+}
+
+// indent indents every line of text by two spaces
+func indent(text string) string {
+	return "  " + strings.ReplaceAll(text, "\n", "\n  ")
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Risk describes how dangerous running a command is
+type Risk int
+
+const (
+	RiskLow Risk = iota
+	RiskMedium
+	RiskHigh
+)
+
+// String returns the lowercase name of the risk level
+func (r Risk) String() string {
+	switch r {
+	case RiskLow:
+		return "low"
+	case RiskMedium:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// ParseRisk converts a risk name such as "low" or "HIGH" into a Risk.
+// Unknown values are treated as medium.
+func ParseRisk(name string) Risk {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low", "none", "safe":
+		return RiskLow
+	case "high", "critical", "dangerous":
+		return RiskHigh
+	default:
+		return RiskMedium
+	}
+}
+
+// rule flags a command pattern with the risk it carries
+type rule struct {
+	pattern *regexp.Regexp
+	risk    Risk
+	reason  string
+}
+
+var rules = []rule{
+	{regexp.MustCompile(`\brm\s+(-[a-zA-Z]*[rR][a-zA-Z]*f|-[a-zA-Z]*f[a-zA-Z]*[rR])[a-zA-Z]*\s+(/|~|\*|\$HOME)(\s|$)`), RiskHigh, "recursively deletes a root, home or wildcard path"},
+	{regexp.MustCompile(`\brm\s+-[a-zA-Z]*[rR]`), RiskMedium, "recursively deletes files"},
+	{regexp.MustCompile(`\bmkfs(\.\w+)?\b`), RiskHigh, "formats a filesystem"},
+	{regexp.MustCompile(`\bdd\b.*\bof=/dev/`), RiskHigh, "writes directly to a device"},
+	{regexp.MustCompile(`>\s*/dev/(sd|nvme|hd|disk)`), RiskHigh, "overwrites a disk device"},
+	{regexp.MustCompile(`:\(\)\s*\{\s*:\|:&\s*\};:`), RiskHigh, "fork bomb"},
+	{regexp.MustCompile(`\bchmod\s+(-R\s+)?[0-7]*777\b`), RiskMedium, "makes files world-writable"},
+	{regexp.MustCompile(`\bchown\s+-R\b`), RiskMedium, "recursively changes ownership"},
+	{regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z)?sh\b`), RiskHigh, "pipes a download straight into a shell"},
+	{regexp.MustCompile(`\bgit\s+push\b.*(--force|-f)\b`), RiskMedium, "force-pushes over remote history"},
+	{regexp.MustCompile(`\bgit\s+(reset\s+--hard|clean\s+-[a-zA-Z]*f)`), RiskMedium, "discards local changes"},
+	{regexp.MustCompile(`\b(shutdown|reboot|halt|poweroff)\b`), RiskMedium, "stops or restarts the machine"},
+	{regexp.MustCompile(`\bkill(all)?\s+-9\b`), RiskMedium, "force-kills processes"},
+	{regexp.MustCompile(`\bsudo\b`), RiskMedium, "runs with root privileges"},
+}
+
+// Assessment is the guard's verdict on a command
+type Assessment struct {
+	Risk    Risk
+	Reasons []string
+}
+
+// Assess checks a command against the built-in rules
+func Assess(command string) Assessment {
+	assessment := Assessment{Risk: RiskLow}
+
+	for _, r := range rules {
+		if !r.pattern.MatchString(command) {
+			continue
+		}
+		if r.risk > assessment.Risk {
+			assessment.Risk = r.risk
+		}
+		assessment.Reasons = append(assessment.Reasons, r.reason)
+	}
+
+	return assessment
+}
+
+// Check assesses a command and raises the result to the risk level declared
+// by the model, so a command is never treated as safer than either source says
+func Check(command string, declared string) Assessment {
+	assessment := Assess(command)
+
+	if declared != "" {
+		if risk := ParseRisk(declared); risk > assessment.Risk {
+			assessment.Risk = risk
+			assessment.Reasons = append(assessment.Reasons, "flagged "+risk.String()+" risk by the AI")
+		}
+	}
+
+	return assessment
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// readOnlyCommands only inspect the system
+var readOnlyCommands = map[string]bool{
+	"ls": true, "pwd": true, "cat": true, "head": true, "tail": true,
+	"wc": true, "grep": true, "egrep": true, "fgrep": true, "rg": true, "tree": true,
+	"file": true, "stat": true, "du": true, "df": true, "which": true, "whereis": true,
+	"type": true, "whoami": true, "id": true, "uname": true,
+	"echo": true, "printf": true,
+	"ps": true, "uptime": true, "free": true, "lsblk": true, "realpath": true,
+	"basename": true, "dirname": true, "sort": true, "cut": true,
+	"tr": true, "diff": true, "cmp": true, "md5sum": true, "sha256sum": true,
+	"jq": true, "test": true, "true": true, "false": true,
+}
+
+// readOnlySubcommands are tools that are read-only only for some subcommands
+var readOnlySubcommands = map[string]map[string]bool{
+	"git":     {"status": true, "log": true, "diff": true, "show": true, "rev-parse": true, "ls-files": true, "blame": true, "describe": true},
+	"go":      {"version": true, "list": true, "doc": true},
+	"docker":  {"ps": true, "images": true, "version": true, "info": true, "inspect": true, "logs": true},
+	"kubectl": {"get": true, "describe": true, "logs": true, "version": true},
+	"npm":     {"ls": true, "list": true, "view": true, "outdated": true},
+	"pip":     {"list": true, "show": true, "freeze": true},
+	"cargo":   {"--version": true, "tree": true},
+}
+
+// unsafeFlags turn otherwise read-only tools into writers or executors, as
+// find -fls, git diff --output and rg --pre do. An output flag is caught
+// with its value attached (sort -ofile) or among grouped short flags
+// (sort -uo file).
+var unsafeFlags = regexp.MustCompile(`(^|\s)((-delete|-exec|-execdir|-ok|-okdir|-fls|-fprint\S*|--pre)(\s|=|$)|--output|-[A-Za-z]*o)`)
+
+// shellWrites detects redirections and command substitutions
+var shellWrites = regexp.MustCompile(`>|<\(|\$\(|` + "`")
+
+// commandSeparators split pipelines and command lists into stages
+var commandSeparators = regexp.MustCompile(`\|\||&&|[|;&]`)
+
+// IsReadOnly reports whether a command only reads state, so it can run
+// without asking. Every stage of a pipeline or command list must qualify.
+// A command of several lines never does, as the shell runs each line as
+// another command.
+func IsReadOnly(command string) bool {
+	command = strings.TrimSpace(command)
+	if command == "" || strings.ContainsAny(command, "\n\r") || shellWrites.MatchString(command) || Assess(command).Risk != RiskLow {
+		return false
+	}
+
+	for _, stage := range commandSeparators.Split(command, -1) {
+		fields := strings.Fields(stage)
+		if len(fields) == 0 || unsafeFlags.MatchString(stage) {
+			return false
+		}
+
+		name := fields[0]
+		switch {
+		case name == "find" || readOnlyCommands[name]:
+		case readOnlySubcommands[name] != nil:
+			if len(fields) < 2 || !readOnlySubcommands[name][fields[1]] {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
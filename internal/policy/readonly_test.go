@@ -0,0 +1,54 @@
+package policy
+
+import "testing"
+
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"ls -la", true},
+		{"git status", true},
+		{"git log --oneline | head -5", true},
+		{"find . -name '*.go'", true},
+		{"rg TODO internal", true},
+
+		// Each line of a multi-line command runs as its own command
+		{"ls\ntouch /tmp/pwned", false},
+		{"echo hi\nrm -f important", false},
+		{"ls\r\ntouch /tmp/pwned", false},
+
+		// Flags that write files or run programs
+		{"git diff --output=/tmp/x", false},
+		{"git log --output /tmp/x", false},
+		{"find . -fls found.txt", false},
+		{"find . -delete", false},
+		{"rg --pre ./evil TODO", false},
+		{"rg --pre=./evil TODO", false},
+		{"sort -ofile in.txt", false},
+		{"sort -uo file in.txt", false},
+		{"git diff --output/tmp/x", false},
+		{"go env -w GOPROXY=evil", false},
+		{"go env -u GOPROXY", false},
+		{"go vet -vettool=./x ./...", false},
+
+		// Leaks every secret in the environment into the transcript
+		{"printenv", false},
+		{"printenv GOTERM_API_KEY", false},
+
+		// Commands that change state with the right arguments
+		{"uniq in.txt out.txt", false},
+		{"date -s 2020-01-01", false},
+		{"hostname newname", false},
+
+		{"ls > out.txt", false},
+		{"git push", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsReadOnly(tt.command); got != tt.want {
+			t.Errorf("IsReadOnly(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+// extension is the file extension of templates in the prompts directory
+const extension = ".tmpl"
+
+// namePattern matches the names templates and custom commands may have
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// funcs are the helpers templates can call besides the text/template builtins
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// Data is what a template can refer to
+type Data struct {
+	Input    string   // what the user typed: a query, question or command
+	Platform string   // runtime.GOOS
+	Shell    string   // the user's shell, e.g. zsh
+	Cwd      string   // the working directory, with ~ for home
+	History  []string // recent commands, oldest first; empty when history context is off
+	ErrorLog string   // the last failed command as JSON, if any
+	Context  string   // the environment block, as shown by config context
+	NoAnswer string   // the token the model answers with when it declines
+	Count    int      // how many commands hp_candidates asks for
+	Style    string   // the rules of the commit message style, for gcm
+	Target   string   // the platform or shell htr translates for
+}
+
+// builtinOnly makes built-in templates ignore files in the prompts directory
+var builtinOnly bool
+
+// IgnoreEdits makes every built-in template render as shipped, whatever the
+// prompts directory holds, so evaluations give the same prompts on any
+// machine. Custom commands still read their templates from the directory.
+func IgnoreEdits() {
+	builtinOnly = true
+}
+
+// Info describes one template
+type Info struct {
+	Name    string
+	Path    string
+	Builtin bool // a built-in template exists under this name
+	Edited  bool // a file in the prompts directory overrides or defines it
+}
+
+// Dir returns the directory templates are read from: prompts.dir when set,
+// so a team can share one, else ~/.goterm/prompts
+func Dir() string {
+	if dir := config.GetConfig().Prompts.Dir; dir != "" {
+		if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				return filepath.Join(home, rest)
+			}
+		}
+		return dir
+	}
+	return filepath.Join(config.GetConfigDir(), "prompts")
+}
+
+// Path returns where the template called name is stored
+func Path(name string) string {
+	return filepath.Join(Dir(), name+extension)
+}
+
+// ValidName reports whether name can be used for a template
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Builtin reports whether name has a built-in template
+func Builtin(name string) bool {
+	_, ok := defaults[name]
+	return ok
+}
+
+// Default returns the built-in template called name
+func Default(name string) (string, bool) {
+	text, ok := defaults[name]
+	return text, ok
+}
+
+// Starter returns the template a new custom command starts from
+func Starter(name string) string {
+	return fmt.Sprintf(starter, name, name)
+}
+
+// Source returns the text of a template: the file in the prompts directory
+// if there is one, else the built-in version
+func Source(name string) (string, bool, error) {
+	if !ValidName(name) {
+		return "", false, fmt.Errorf("invalid prompt name %q", name)
+	}
+	if text, ok := defaults[name]; ok && builtinOnly {
+		return text, false, nil
+	}
+
+	data, err := os.ReadFile(Path(name))
+	if err == nil {
+		return string(data), true, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", false, err
+	}
+
+	if text, ok := defaults[name]; ok {
+		return text, false, nil
+	}
+	return "", false, fmt.Errorf("no prompt template named %q", name)
+}
+
+// List returns the built-in templates and any others in the prompts
+// directory, sorted by name
+func List() ([]Info, error) {
+	found := map[string]*Info{}
+	for name := range defaults {
+		found[name] = &Info{Name: name, Path: Path(name), Builtin: true}
+	}
+
+	entries, err := os.ReadDir(Dir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), extension)
+		if !ok || entry.IsDir() || !ValidName(name) {
+			continue
+		}
+		if info, ok := found[name]; ok {
+			info.Edited = true
+			continue
+		}
+		found[name] = &Info{Name: name, Path: Path(name), Edited: true}
+	}
+
+	infos := make([]Info, 0, len(found))
+	for _, info := range found {
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Parse checks that text is a valid template. The conventions template is
+// defined alongside it, so includes resolve as they will when rendered.
+func Parse(name, text string) (*template.Template, error) {
+	conventions, _, err := Source(Conventions)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := template.New(name).Funcs(funcs)
+	if name != Conventions {
+		if _, err := tmpl.New(Conventions).Parse(conventions); err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", Conventions, err)
+		}
+	}
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Render executes the template called name with data
+func Render(name string, data Data) (string, error) {
+	text, _, err := Source(name)
+	if err != nil {
+		return "", err
+	}
+	return Execute(name, text, data)
+}
+
+// Execute parses text as the template called name and executes it
+func Execute(name, text string, data Data) (string, error) {
+	tmpl, err := Parse(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("prompt %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// Save validates text and writes it as the template called name
+func Save(name, text string) error {
+	if !ValidName(name) {
+		return fmt.Errorf("invalid prompt name %q", name)
+	}
+	if _, err := Parse(name, text); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(Path(name), []byte(text), 0600)
+}
+
+// Reset deletes the file for name, restoring the built-in template or
+// removing a custom one. It reports whether there was a file.
+func Reset(name string) (bool, error) {
+	if !ValidName(name) {
+		return false, fmt.Errorf("invalid prompt name %q", name)
+	}
+	err := os.Remove(Path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Fingerprint identifies the edited templates a prompt depends on, so
+// cached answers from another version of them aren't reused. It is empty
+// while both name and the conventions are built-in.
+func Fingerprint(name string) string {
+	hash := sha256.New()
+	edited := false
+	for _, part := range []string{name, Conventions} {
+		text, custom, err := Source(part)
+		if err != nil {
+			continue
+		}
+		edited = edited || custom
+		hash.Write([]byte(text + "\x00"))
+	}
+	if !edited {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
@@ -0,0 +1,199 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Finding records one secret that was removed from the text
+type Finding struct {
+	Detector string
+	Preview  string
+}
+
+// detector finds one kind of secret. Group selects the submatch that is
+// replaced; 0 replaces the whole match.
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	group   int
+}
+
+var builtinDetectors = []detector{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`), 0},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`), 0},
+	{"aws-access-key", regexp.MustCompile(`\b(AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`), 0},
+	{"aws-secret", regexp.MustCompile(`(?i)(aws_secret_access_key|aws_session_token)["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{16,})`), 2},
+	{"gcp-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), 0},
+	{"github-token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{30,}|github_pat_[A-Za-z0-9_]{22,})\b`), 0},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`), 0},
+	{"auth-header", regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic|token)\s+)([^\s"']+)`), 2},
+	{"url-password", regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]*://[^:/\s@\[\]]+:)([^@\s/]+)@`), 2},
+	{"secret-assignment", regexp.MustCompile(`(?i)\b([a-z0-9_]*(?:secret|token|password|passwd|api_?key|access_?key)[a-z0-9_]*)["']?\s*[=:]\s*["']?([^\s"',;]{4,})`), 2},
+}
+
+// tokenPattern finds candidate strings for the entropy check
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+=_-]{24,}`)
+
+// placeholderPattern matches text that has already been redacted
+var placeholderPattern = regexp.MustCompile(`^\[REDACTED:[a-z-]+\]$`)
+
+// Redactor removes secrets from text before it leaves the machine
+type Redactor struct {
+	detectors []detector
+	entropy   bool
+	home      string
+}
+
+// New creates a redactor with the built-in detectors plus the given custom
+// regular expressions. If a pattern has a capture group, only the first
+// group is replaced.
+func New(custom []string, entropy bool) (*Redactor, error) {
+	r := &Redactor{
+		detectors: append([]detector{}, builtinDetectors...),
+		entropy:   entropy,
+	}
+
+	for i, expr := range custom {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", expr, err)
+		}
+		group := 0
+		if pattern.NumSubexp() > 0 {
+			group = 1
+		}
+		r.detectors = append(r.detectors, detector{fmt.Sprintf("custom-%d", i+1), pattern, group})
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		r.home = home
+	}
+
+	return r, nil
+}
+
+// Redact returns text with secrets replaced by [REDACTED:<kind>] markers
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	var findings []Finding
+
+	for _, d := range r.detectors {
+		text = replaceGroup(text, d, &findings)
+	}
+
+	if r.entropy {
+		text = tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+			if !looksRandom(token) {
+				return token
+			}
+			findings = append(findings, Finding{Detector: "high-entropy", Preview: preview(token)})
+			return "[REDACTED:high-entropy]"
+		})
+	}
+
+	// Home directory paths reveal the username; they are not counted as secrets
+	if r.home != "" {
+		text = strings.ReplaceAll(text, r.home, "~")
+	}
+
+	return text, findings
+}
+
+// replaceGroup replaces the detector's capture group in every match
+func replaceGroup(text string, d detector, findings *[]Finding) string {
+	matches := d.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*d.group], m[2*d.group+1]
+		if start < 0 || placeholderPattern.MatchString(text[start:end]) {
+			continue
+		}
+		*findings = append(*findings, Finding{Detector: d.name, Preview: preview(text[start:end])})
+		b.WriteString(text[last:start])
+		b.WriteString("[REDACTED:" + d.name + "]")
+		last = end
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// looksRandom reports whether a token is likely a generated secret
+func looksRandom(token string) bool {
+	if strings.Contains(token, "REDACTED") {
+		return false
+	}
+
+	hasUpper, hasLower, hasDigit := false, false, false
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+	}
+	// Hex digests and words rarely mix all three character classes
+	if !(hasUpper && hasLower && hasDigit) {
+		return false
+	}
+
+	return shannonEntropy(token) >= 4.0
+}
+
+// shannonEntropy returns the bits of entropy per character of s
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+
+	entropy := 0.0
+	length := float64(len(s))
+	for _, n := range counts {
+		p := float64(n) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// preview shows just enough of a secret to recognize it, cutting between
+// characters rather than inside one
+func preview(secret string) string {
+	runes := []rune(secret)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + "…" + fmt.Sprintf("(%d chars)", len(runes))
+}
+
+// Summary describes findings as "2 github-token, 1 jwt"
+func Summary(findings []Finding) string {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Detector]++
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%d %s", counts[name], name)
+	}
+	return strings.Join(parts, ", ")
+}
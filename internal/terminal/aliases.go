@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -127,29 +128,89 @@ func (am *AliasManager) ListAliases() []Alias {
 	return aliases
 }
 
-// ExpandCommand expands any aliases in the given command
+// ExpandCommand expands any aliases in the given command. Words after an
+// alias are appended to it, or fill its $1 to $9 and $@ parameters when it
+// has any.
 func (am *AliasManager) ExpandCommand(input string) string {
 	if err := am.Initialize(); err != nil {
 		return input
 	}
 
-	fields := strings.Fields(input)
-	if len(fields) == 0 {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
 		return input
 	}
+	name, rest, _ := strings.Cut(trimmed, " ")
+	rest = strings.TrimSpace(rest)
 
 	// Check if first word is an alias
-	if alias, err := am.GetAlias(fields[0]); err == nil {
-		// Replace the alias with its command
-		expanded := alias.Command
-		if len(fields) > 1 {
-			// Append any arguments after the alias
-			expanded += " " + strings.Join(fields[1:], " ")
+	alias, err := am.GetAlias(name)
+	if err != nil {
+		return input
+	}
+
+	if len(aliasParams(alias.Command)) == 0 {
+		// Append any arguments after the alias
+		if rest == "" {
+			return alias.Command
 		}
-		return expanded
+		return alias.Command + " " + rest
 	}
+	return expandParams(alias.Command, splitWords(rest), rest)
+}
+
+// aliasParams lists the parameters used in command. As in a shell
+// function, a $1 inside single quotes is plain text.
+func aliasParams(command string) []string {
+	var params []string
+	scanParams(command, func(param string) string {
+		if !slices.Contains(params, param) {
+			params = append(params, param)
+		}
+		return param
+	})
+	return params
+}
 
-	return input
+// expandParams fills the parameters of command with args, and $@ with all
+// of rest. Missing arguments expand to nothing.
+func expandParams(command string, args []string, rest string) string {
+	return scanParams(command, func(param string) string {
+		if param == "$@" {
+			return rest
+		}
+		n := int(param[1] - '0')
+		if n > len(args) {
+			return ""
+		}
+		return args[n-1]
+	})
+}
+
+// scanParams replaces each parameter outside single quotes with what fill
+// returns for it
+func scanParams(command string, fill func(param string) string) string {
+	var b strings.Builder
+	inSingle, inDouble := false, false
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\' && !inSingle && i+1 < len(command):
+			b.WriteString(command[i : i+2])
+			i++
+			continue
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			inDouble = !inDouble
+		case c == '$' && !inSingle && i+1 < len(command) && strings.IndexByte("123456789@", command[i+1]) >= 0:
+			b.WriteString(fill(command[i : i+2]))
+			i++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
 }
 
 // saveAliases saves all aliases to the config file
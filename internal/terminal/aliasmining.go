@@ -0,0 +1,365 @@
+package terminal
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Thresholds for alias suggestions
+const (
+	minAliasUses   = 3  // history entries a pattern must cover
+	minAliasLength = 12 // characters a pattern must have to be worth naming
+	maxExamples    = 3
+)
+
+// AliasSuggestion is a repeated command, or family of commands, that an
+// alias would shorten. Parameters are written $1 as in a shell function.
+type AliasSuggestion struct {
+	Name     string
+	Command  string
+	Uses     int      // history entries it would have replaced
+	Saved    int      // keystrokes those entries would have saved
+	Examples []string // matching entries, most recent first
+
+	fixed int // characters of the entries the alias name stands in for
+}
+
+// Params returns how many parameters the suggestion takes
+func (s AliasSuggestion) Params() int {
+	return len(aliasParams(s.Command))
+}
+
+// Rename changes the suggested name and the keystrokes it saves
+func (s *AliasSuggestion) Rename(name string) {
+	s.Name = name
+	s.Saved = s.fixed - s.Uses*len(name)
+}
+
+// Generalize replaces the command of s with a more general one, such as a
+// model proposes, and recounts the entries of commands it covers and the
+// keystrokes it saves. It reports false and leaves s alone when an example
+// isn't an invocation of command or the alias would save nothing.
+func (s *AliasSuggestion) Generalize(command string, commands []string) bool {
+	for _, example := range s.Examples {
+		if _, ok := aliasArgs(command, example); !ok {
+			return false
+		}
+	}
+
+	g := AliasSuggestion{Command: command}
+	for i := len(commands) - 1; i >= 0; i-- {
+		entry := strings.TrimSpace(commands[i])
+		args, ok := aliasArgs(command, entry)
+		if !ok {
+			continue
+		}
+		g.Uses++
+		g.fixed += len(entry)
+		if args != "" {
+			g.fixed -= len(args) + 1
+		}
+		if len(g.Examples) < maxExamples && !slices.Contains(g.Examples, entry) {
+			g.Examples = append(g.Examples, entry)
+		}
+	}
+	g.Rename(s.Name)
+	if g.Uses == 0 || g.Saved <= 0 {
+		return false
+	}
+
+	*s = g
+	return true
+}
+
+// aliasArgs returns the arguments that make an alias for command expand to
+// entry, and whether there are any
+func aliasArgs(command, entry string) (string, bool) {
+	params := aliasParams(command)
+	if len(params) == 0 {
+		if entry == command {
+			return "", true
+		}
+		if rest, ok := strings.CutPrefix(entry, command+" "); ok {
+			return strings.TrimSpace(rest), true
+		}
+		return "", false
+	}
+
+	// Match the text around the parameters, then check that the arguments
+	// found expand back to entry
+	var order []string
+	parts := strings.Split(scanParams(command, func(param string) string {
+		order = append(order, param)
+		return "\x00"
+	}), "\x00")
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for i, part := range parts {
+		pattern.WriteString(regexp.QuoteMeta(part))
+		if i < len(order) {
+			if order[i] == "$@" {
+				pattern.WriteString("(.*)")
+			} else {
+				pattern.WriteString(`(\S+)`)
+			}
+		}
+	}
+	pattern.WriteString("$")
+
+	match := regexp.MustCompile(pattern.String()).FindStringSubmatch(entry)
+	if match == nil {
+		return "", false
+	}
+
+	var rest string
+	if i := slices.Index(order, "$@"); i >= 0 {
+		rest = match[i+1]
+	} else {
+		args := make([]string, 9)
+		last := 0
+		for i, param := range order {
+			n := int(param[1] - '0')
+			args[n-1] = match[i+1]
+			last = max(last, n)
+		}
+		for i := range args[:last] {
+			if args[i] == "" {
+				args[i] = "_" // a parameter the command doesn't use
+			}
+		}
+		rest = strings.Join(args[:last], " ")
+	}
+
+	if expandParams(command, splitWords(rest), rest) != entry {
+		return "", false
+	}
+	return rest, true
+}
+
+// SuggestAliases mines commands, oldest first, for aliases worth adding:
+// long commands repeated as they are, long leading words shared by commands
+// that go on differently, and commands that differ in one word, which
+// become a function of that word. Names that taken reports as in use get a
+// number. The best limit suggestions are returned, most keystrokes saved
+// first.
+func SuggestAliases(commands []string, taken func(string) bool, limit int) []AliasSuggestion {
+	found := map[string]*AliasSuggestion{}
+	note := func(command, entry string, fixed int) {
+		s, ok := found[command]
+		if !ok {
+			s = &AliasSuggestion{Command: command}
+			found[command] = s
+		}
+		s.Uses++
+		s.fixed += fixed
+		if len(s.Examples) < maxExamples && !slices.Contains(s.Examples, entry) {
+			s.Examples = append(s.Examples, entry)
+		}
+	}
+
+	// Count every pattern first, then keep those that qualify
+	exact := map[string]int{}
+	prefixes := map[string]map[string]bool{} // prefix -> words that follow it
+	slots := map[string]map[string]bool{}    // pattern -> words filling $1
+	for _, entry := range commands {
+		entry = strings.TrimSpace(entry)
+		words := splitWords(entry)
+		if len(words) < 2 || len(aliasParams(entry)) > 0 {
+			continue
+		}
+		exact[entry]++
+		for k := 2; k < len(words); k++ {
+			prefix := strings.Join(words[:k], " ")
+			if prefixes[prefix] == nil {
+				prefixes[prefix] = map[string]bool{}
+			}
+			prefixes[prefix][words[k]] = true
+		}
+		// The last word is left to the prefixes, which take trailing arguments
+		for i := 1; i < len(words)-1; i++ {
+			pattern := slotPattern(words, i)
+			if slots[pattern] == nil {
+				slots[pattern] = map[string]bool{}
+			}
+			slots[pattern][words[i]] = true
+		}
+	}
+
+	// Newest entries first, so examples are the most recent ones
+	for i := len(commands) - 1; i >= 0; i-- {
+		entry := strings.TrimSpace(commands[i])
+		words := splitWords(entry)
+		if len(words) < 2 || len(aliasParams(entry)) > 0 {
+			continue
+		}
+
+		if exact[entry] >= minAliasUses && len(entry) >= minAliasLength {
+			note(entry, entry, len(entry))
+		}
+		for k := 2; k < len(words); k++ {
+			prefix := strings.Join(words[:k], " ")
+			if len(prefixes[prefix]) >= 2 && len(prefix) >= minAliasLength {
+				note(prefix, entry, len(prefix))
+			}
+		}
+		for i := 1; i < len(words)-1; i++ {
+			pattern := slotPattern(words, i)
+			if len(slots[pattern]) >= 2 && len(pattern)-len("$1") >= minAliasLength {
+				// "name arg" replaces the entry, so the arg and one space stay typed
+				note(pattern, entry, len(entry)-len(words[i])-1)
+			}
+		}
+	}
+
+	var suggestions []AliasSuggestion
+	for _, s := range found {
+		if s.Uses >= minAliasUses {
+			suggestions = append(suggestions, *s)
+		}
+	}
+	// Rank by the characters covered, as names aren't chosen yet
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].fixed != suggestions[j].fixed {
+			return suggestions[i].fixed > suggestions[j].fixed
+		}
+		return suggestions[i].Command < suggestions[j].Command
+	})
+
+	// One alias per family: "git commit" and "git commit -m" overlap
+	var chosen []AliasSuggestion
+	names := map[string]bool{}
+	for _, s := range suggestions {
+		if len(chosen) == limit {
+			break
+		}
+		if overlaps(s, chosen) {
+			continue
+		}
+		s.Rename(uniqueName(aliasName(s.Command), func(name string) bool {
+			return names[name] || (taken != nil && taken(name))
+		}))
+		if s.Saved <= 0 {
+			continue
+		}
+		names[s.Name] = true
+		chosen = append(chosen, s)
+	}
+
+	sort.SliceStable(chosen, func(i, j int) bool { return chosen[i].Saved > chosen[j].Saved })
+	return chosen
+}
+
+// slotPattern is words with the one at i replaced by $1
+func slotPattern(words []string, i int) string {
+	pattern := make([]string, len(words))
+	copy(pattern, words)
+	pattern[i] = "$1"
+	return strings.Join(pattern, " ")
+}
+
+// overlaps reports whether s extends, or is extended by, a chosen command
+func overlaps(s AliasSuggestion, chosen []AliasSuggestion) bool {
+	for _, c := range chosen {
+		if strings.HasPrefix(s.Command+" ", c.Command+" ") || strings.HasPrefix(c.Command+" ", s.Command+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasName builds a name from the initials of a command's words, so
+// "docker compose up -d" becomes "dcud"
+func aliasName(command string) string {
+	var name strings.Builder
+	for i, word := range splitWords(command) {
+		if i == 0 {
+			word = filepath.Base(word)
+		}
+		word = strings.TrimLeft(word, "-")
+		if word == "" || !isAliasLetter(word[0]) {
+			continue
+		}
+		name.WriteByte(lowerASCII(word[0]))
+		if name.Len() == 4 {
+			break
+		}
+	}
+
+	if name.Len() < 2 {
+		// A single letter is too easy to type by accident
+		first := filepath.Base(splitWords(command)[0])
+		name.Reset()
+		for i := 0; i < len(first) && name.Len() < 3; i++ {
+			if isAliasLetter(first[i]) {
+				name.WriteByte(lowerASCII(first[i]))
+			}
+		}
+	}
+	if name.Len() < 2 {
+		return "al"
+	}
+	return name.String()
+}
+
+// uniqueName numbers name until taken no longer claims it
+func uniqueName(name string, taken func(string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + strconv.Itoa(i); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+func isAliasLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func lowerASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// splitWords splits a command line at unquoted blanks, keeping quotes and
+// escapes as typed so the words can be joined back into the same command
+func splitWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ' ' || r == '\t':
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteRune(r)
+	}
+
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
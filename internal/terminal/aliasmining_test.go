@@ -0,0 +1,62 @@
+package terminal
+
+import "testing"
+
+func TestAliasArgs(t *testing.T) {
+	tests := []struct {
+		command, entry string
+		args           string
+		ok             bool
+	}{
+		{"git status", "git status", "", true},
+		{"docker compose", "docker compose up -d", "up -d", true},
+		{"docker compose", "docker composer", "", false},
+		{"kubectl logs -f $1 -n prod", "kubectl logs -f api -n prod", "api", true},
+		{"kubectl logs -f $1 -n prod", "kubectl logs -f api -n staging", "", false},
+		{"cp $1 $2.bak", "cp notes.txt notes.txt.bak", "notes.txt notes.txt", true},
+		{"ssh $1 tail -f $1.log", "ssh web tail -f db.log", "", false},
+		{"git commit -m $@", "git commit -m fix the build", "fix the build", true},
+	}
+
+	for _, tt := range tests {
+		args, ok := aliasArgs(tt.command, tt.entry)
+		if ok != tt.ok || args != tt.args {
+			t.Errorf("aliasArgs(%q, %q) = %q, %v; want %q, %v", tt.command, tt.entry, args, ok, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestGeneralize(t *testing.T) {
+	commands := []string{
+		"kubectl logs -f api -n prod",
+		"kubectl logs -f web -n prod",
+		"kubectl logs -f api -n prod",
+		"kubectl logs -f worker -n prod",
+		"ls",
+	}
+	s := AliasSuggestion{Command: "kubectl logs -f api -n prod", Examples: []string{"kubectl logs -f api -n prod"}}
+	s.Rename("klp")
+
+	if s.Generalize("kubectl logs -f $1 -n staging", commands) {
+		t.Fatal("Generalize accepted a command that doesn't produce the examples")
+	}
+	if s.Command != "kubectl logs -f api -n prod" {
+		t.Fatalf("a rejected command changed the suggestion to %q", s.Command)
+	}
+
+	if !s.Generalize("kubectl logs -f $1 -n prod", commands) {
+		t.Fatal("Generalize rejected a command that produces the examples")
+	}
+	if s.Uses != 4 || len(s.Examples) != 3 || s.Examples[0] != "kubectl logs -f worker -n prod" {
+		t.Errorf("Uses = %d, Examples = %q; want 4 entries, newest first", s.Uses, s.Examples)
+	}
+	// Each entry is typed as "klp <name>" instead
+	want := 0
+	for _, entry := range commands[:4] {
+		want += len(entry)
+	}
+	want -= len("klp api") + len("klp web") + len("klp api") + len("klp worker")
+	if s.Saved != want {
+		t.Errorf("Saved = %d, want %d", s.Saved, want)
+	}
+}
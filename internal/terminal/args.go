@@ -0,0 +1,120 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a command line into words the way a POSIX shell would
+// for simple commands: single and double quotes, backslash escapes and
+// bash's $'...' strings are honoured. Pipes, redirects and expansions are
+// not.
+func SplitArgs(line string) ([]string, error) {
+	var (
+		args         []string
+		word         strings.Builder
+		inWord       bool
+		runes        = []rune(line)
+		unterminated = func(q string) error { return fmt.Errorf("unterminated %s in command", q) }
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case r == '\\':
+			inWord = true
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' {
+					word.WriteRune(runes[i])
+				}
+			}
+
+		case r == '\'':
+			inWord = true
+			end := strings.IndexRune(string(runes[i+1:]), '\'')
+			if end < 0 {
+				return nil, unterminated("single quote")
+			}
+			segment := []rune(string(runes[i+1:])[:end])
+			word.WriteString(string(segment))
+			i += len(segment) + 1
+
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			inWord = true
+			i += 2
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					word.WriteRune(unescape(runes[i]))
+					continue
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, unterminated("$' string")
+			}
+
+		case r == '"':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+					i++
+					if runes[i] != '\n' {
+						word.WriteRune(runes[i])
+					}
+					continue
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, unterminated("double quote")
+			}
+
+		default:
+			inWord = true
+			word.WriteRune(r)
+		}
+	}
+
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// unescape maps the character after a backslash in a $'...' string
+func unescape(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	}
+	return r
+}
+
+// Quote returns s as a single shell word that SplitArgs, bash and zsh all
+// read back unchanged. Text with line breaks uses a $'...' string so the
+// command stays on one line.
+func Quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n\r'\"\\$`|&;<>()*?[]{}#~!") {
+		return s
+	}
+
+	if strings.ContainsAny(s, "\n\r\t") {
+		replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+		return "$'" + replacer.Replace(s) + "'"
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,113 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// defaultCaptureLimit is used when no positive capture limit is configured
+const defaultCaptureLimit = 16000
+
+// BoundedBuffer keeps the beginning and end of everything written to it,
+// dropping the middle once the limit is reached
+type BoundedBuffer struct {
+	limit   int
+	head    []byte
+	tail    []byte
+	dropped int
+}
+
+// NewBoundedBuffer creates a buffer holding at most limit bytes
+func NewBoundedBuffer(limit int) *BoundedBuffer {
+	return &BoundedBuffer{limit: limit}
+}
+
+// Write implements io.Writer and never fails
+func (b *BoundedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	headLimit := b.limit / 2
+
+	if room := headLimit - len(b.head); room > 0 {
+		take := min(room, len(p))
+		b.head = append(b.head, p[:take]...)
+		p = p[take:]
+	}
+
+	b.tail = append(b.tail, p...)
+	if tailLimit := b.limit - headLimit; len(b.tail) > tailLimit {
+		b.dropped += len(b.tail) - tailLimit
+		b.tail = b.tail[len(b.tail)-tailLimit:]
+	}
+
+	return n, nil
+}
+
+// String returns the kept output with a marker where bytes were dropped
+func (b *BoundedBuffer) String() string {
+	if b.dropped == 0 {
+		return string(b.head) + string(b.tail)
+	}
+	return fmt.Sprintf("%s\n… [%d bytes omitted] …\n%s", b.head, b.dropped, b.tail)
+}
+
+// CaptureResult is the outcome of a captured command
+type CaptureResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// waitDelay is how long a killed command's children may keep its output
+// open before RunCaptured stops waiting for them
+const waitDelay = time.Second
+
+// RunCaptured runs a command line through the shell, showing its output
+// as it runs and keeping up to limit bytes of each stream. Stdin is not
+// connected, so commands cannot wait for input. The command is killed when
+// ctx is done or after timeout, if it is positive, so one that never ends,
+// like tail -f, can't hang the caller.
+func RunCaptured(ctx context.Context, command string, limit int, timeout time.Duration) CaptureResult {
+	stdout := NewBoundedBuffer(limit)
+	stderr := NewBoundedBuffer(limit)
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = io.MultiWriter(os.Stdout, stdout)
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	cmd.WaitDelay = waitDelay
+
+	start := time.Now()
+	err := cmd.Run()
+	result := CaptureResult{Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.ExitCode = 124
+		fmt.Fprintf(stderr, "\nkilled after %s\n", timeout)
+	case ctx.Err() != nil:
+		result.ExitCode = 130
+		fmt.Fprintln(stderr, "\ninterrupted")
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = 127
+		fmt.Fprintln(stderr, err)
+	}
+
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	return result
+}
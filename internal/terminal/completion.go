@@ -1,6 +1,9 @@
 package terminal
 
 import (
+	"github/0PrashantYadav0/GO-TERM/internal/prompts"
+	"github/0PrashantYadav0/GO-TERM/internal/translate"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -42,9 +45,14 @@ func (c *Completer) Complete(input string) []string {
 			subcommands := []string{"create", "switch", "list", "close", "layout"}
 			return filterByPrefix(subcommands, parts[1])
 		}
+	case "history":
+		if len(parts) == 2 {
+			subcommands := []string{"search", "describe"}
+			return filterByPrefix(subcommands, parts[1])
+		}
 	case "alias", "a":
 		if len(parts) == 2 {
-			subcommands := []string{"add", "remove", "list"}
+			subcommands := []string{"add", "remove", "list", "suggest"}
 			return filterByPrefix(subcommands, parts[1])
 		}
 	case "bookmark", "bm":
@@ -52,6 +60,63 @@ func (c *Completer) Complete(input string) []string {
 			subcommands := []string{"add", "remove", "list", "goto"}
 			return filterByPrefix(subcommands, parts[1])
 		}
+	case "config":
+		if len(parts) == 2 {
+			subcommands := []string{"list", "get", "set", "context", "auth", "clipboard"}
+			return filterByPrefix(subcommands, parts[1])
+		}
+		if len(parts) == 3 && parts[1] == "auth" {
+			return filterByPrefix([]string{"login", "status", "logout"}, parts[2])
+		}
+	case "agent":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"list", "show", "replay", "export", "--steps"}, parts[1])
+		}
+	case "he":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"--no-ai"}, parts[1])
+		}
+	case "hx":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"--diff"}, parts[1])
+		}
+	case "htr":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"--to", "--no-ai"}, parts[1])
+		}
+		if len(parts) == 3 && parts[1] == "--to" {
+			return filterByPrefix(translate.Targets, parts[2])
+		}
+	case "hscript":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"--goterm"}, parts[1])
+		}
+	case "gcm":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"--style"}, parts[1])
+		}
+		if len(parts) == 3 && parts[1] == "--style" {
+			return filterByPrefix([]string{"conventional", "gitmoji", "plain"}, parts[2])
+		}
+	case "prompts":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"list", "edit", "reset", "test", "add", "remove"}, parts[1])
+		}
+		if len(parts) == 3 && parts[1] != "add" {
+			var names []string
+			infos, _ := prompts.List()
+			for _, info := range infos {
+				names = append(names, info.Name)
+			}
+			return filterByPrefix(names, parts[2])
+		}
+	case "ai":
+		if len(parts) == 2 {
+			return filterByPrefix([]string{"cache", "usage"}, parts[1])
+		}
+		if len(parts) == 3 && parts[1] == "cache" {
+			return filterByPrefix([]string{"stats", "clear"}, parts[2])
+		}
 	}
 
 	// Continue with normal completion
@@ -138,17 +203,28 @@ func (c *Completer) CompleteInternalCommands(prefix string) []string {
 		"hp",
 		"he",
 		"hm",
+		"hx",
+		"htr",
+		"hscript",
+		"gcm",
 		"chat",
+		"agent",
 		"history",
 		"exit",
 		"session",
 		"alias",
 		"bookmark",
 		"config",
+		"ai",
+		"prompts",
 		"update",
 		"version",
 		"help",
 	}
+	// User-defined AI commands
+	for name := range config.GetConfig().Prompts.Commands {
+		commands = append(commands, name)
+	}
 
 	var matches []string
 	for _, cmd := range commands {
@@ -32,84 +32,118 @@ const (
 	Comment
 )
 
-// Token represents a part of a command with its type
+// Token represents a part of a command with its type and byte offset
 type Token struct {
 	Type  TokenType
 	Value string
+	Pos   int
+}
+
+// assignmentPattern matches a variable assignment such as FOO=1
+var assignmentPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*=`)
+
+// tokenPattern matches one token type at the start of the remaining input
+type tokenPattern struct {
+	tokenType TokenType
+	pattern   *regexp.Regexp
 }
 
 // Highlighter handles syntax highlighting for terminal commands
 type Highlighter struct {
-	patterns map[TokenType]*regexp.Regexp
+	patterns []tokenPattern
 }
 
 // NewHighlighter creates a new syntax highlighter
 func NewHighlighter() *Highlighter {
-	h := &Highlighter{
-		patterns: make(map[TokenType]*regexp.Regexp),
+	// Patterns are tried in order, so more specific ones come first
+	return &Highlighter{
+		patterns: []tokenPattern{
+			{Comment, regexp.MustCompile(`^#.*$`)},
+			{QuotedString, regexp.MustCompile(`^'[^']*'|^"(\\.|[^"\\])*"`)},
+			{Variable, regexp.MustCompile(`^\$\{?[a-zA-Z0-9_]+\}?`)},
+			{Pipe, regexp.MustCompile(`^(\|\||&&|\||;|&)`)},
+			{Redirection, regexp.MustCompile(`^([0-9]?>>?(&[0-9])?|&>>?|<<?)`)},
+			{Flag, regexp.MustCompile(`^-{1,2}[a-zA-Z0-9_][a-zA-Z0-9_\-]*(=[^\s|;&<>]*)?`)},
+		},
 	}
-
-	// Initialize regex patterns for different token types
-	h.patterns[Command] = regexp.MustCompile(`^[a-zA-Z0-9_\-\.]+`)
-	h.patterns[Flag] = regexp.MustCompile(`^-{1,2}[a-zA-Z0-9_\-]+`)
-	h.patterns[Pipe] = regexp.MustCompile(`^\|`)
-	h.patterns[Redirection] = regexp.MustCompile(`^[><]{1,2}`)
-	h.patterns[Variable] = regexp.MustCompile(`^\$[a-zA-Z0-9_]+`)
-	h.patterns[QuotedString] = regexp.MustCompile(`^(['"])(.*?)(\1)`)
-	h.patterns[Comment] = regexp.MustCompile(`^#.*$`)
-
-	return h
 }
 
-// Tokenize splits a command into tokens
+// Tokenize splits a command into tokens. The first word of each pipeline
+// stage or command list entry is a Command; later words are Arguments.
 func (h *Highlighter) Tokenize(command string) []Token {
 	var tokens []Token
-	remaining := strings.TrimSpace(command)
+	pos := 0
+	expectCommand := true
 
-	for len(remaining) > 0 {
-		matched := false
+	for pos < len(command) {
+		remaining := command[pos:]
 
 		// Skip whitespace
-		if spaces := strings.TrimLeft(remaining, " \t"); len(spaces) != len(remaining) {
-			remaining = spaces
+		if trimmed := strings.TrimLeft(remaining, " \t\n"); len(trimmed) != len(remaining) {
+			pos += len(remaining) - len(trimmed)
 			continue
 		}
 
-		// Try to match each token type
-		for tokenType, pattern := range h.patterns {
-			if match := pattern.FindString(remaining); match != "" {
-				tokens = append(tokens, Token{Type: tokenType, Value: match})
-				remaining = strings.TrimPrefix(remaining, match)
-				matched = true
+		token := Token{Type: Argument, Pos: pos}
+		for _, p := range h.patterns {
+			if match := p.pattern.FindString(remaining); match != "" {
+				token.Type, token.Value = p.tokenType, match
 				break
 			}
 		}
 
-		// If no match, treat as argument
-		if !matched {
-			endIdx := strings.IndexAny(remaining, " \t|><")
-			if endIdx == -1 {
-				endIdx = len(remaining)
+		// Anything else is a word up to the next space or operator
+		if token.Value == "" {
+			token.Value = remaining[:wordEnd(remaining)]
+			if expectCommand && !assignmentPattern.MatchString(token.Value) {
+				token.Type = Command
 			}
-			tokens = append(tokens, Token{Type: Argument, Value: remaining[:endIdx]})
-			remaining = remaining[endIdx:]
 		}
+
+		switch token.Type {
+		case Pipe:
+			expectCommand = true
+		case Command:
+			expectCommand = false
+		case Argument, QuotedString, Variable:
+			// Environment assignments such as FOO=1 come before the command
+			if !expectCommand || !assignmentPattern.MatchString(token.Value) {
+				expectCommand = false
+			}
+		}
+
+		tokens = append(tokens, token)
+		pos += len(token.Value)
 	}
 
 	return tokens
 }
 
-// Highlight adds color to a command string
+// wordEnd returns the length of the word at the start of s, which ends at
+// unescaped whitespace or a shell operator
+func wordEnd(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ' ', '\t', '\n', '|', ';', '&', '<', '>':
+			return max(i, 1)
+		}
+	}
+	return len(s)
+}
+
+// Highlight adds color to a command string, keeping its spacing
 func (h *Highlighter) Highlight(command string) string {
-	tokens := h.Tokenize(command)
 	result := ""
+	end := 0
 
-	for _, token := range tokens {
-		colored := h.colorizeToken(token)
-		result += colored
+	for _, token := range h.Tokenize(command) {
+		result += command[end:token.Pos] + h.colorizeToken(token)
+		end = token.Pos + len(token.Value)
 	}
 
-	return result + Reset
+	return result + command[end:] + Reset
 }
 
 // colorizeToken applies colors based on token type
@@ -2,18 +2,39 @@ package terminal
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 const HISTORY_LIMIT = 1000
 
-// History manages command history
+// History manages command history. It is safe for concurrent use, as a fix
+// fetched in the background reads it while the prompt adds to it.
 type History struct {
+	mu       sync.Mutex
 	filePath string
 	commands []string
+
+	// The search index, and the files with where and when each command
+	// ran and what it does
+	index            *HistoryIndex
+	runsPath         string
+	descriptionsPath string
+	runs             int
+}
+
+// historyRun is where and when a command ran. Runs are kept beside the
+// history file, which only has the commands.
+type historyRun struct {
+	Command string    `json:"command"`
+	Dir     string    `json:"dir,omitempty"`
+	Time    time.Time `json:"time"`
 }
 
 // NewHistory creates a new history manager
@@ -27,12 +48,16 @@ func NewHistory() *History {
 	filePath := filepath.Join(homeDir, ".goterm_history")
 
 	h := &History{
-		filePath: filePath,
-		commands: []string{},
+		filePath:         filePath,
+		commands:         []string{},
+		index:            NewHistoryIndex(),
+		runsPath:         filepath.Join(config.GetConfigDir(), "history_runs.jsonl"),
+		descriptionsPath: filepath.Join(config.GetConfigDir(), "history_descriptions.json"),
 	}
 
 	// Load existing history
 	h.load()
+	h.loadIndex()
 
 	return h
 }
@@ -65,11 +90,15 @@ func (h *History) load() {
 
 // Add adds a command to the history
 func (h *History) Add(command string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	command = strings.TrimSpace(command)
 	if command == "" {
 		return
 	}
 
+	h.recordRun(command)
+
 	// Don't add duplicates consecutively
 	if len(h.commands) > 0 && h.commands[len(h.commands)-1] == command {
 		return
@@ -105,6 +134,8 @@ func (h *History) save() {
 
 // Show displays the command history
 func (h *History) Show() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	for i, cmd := range h.commands {
 		fmt.Printf("%d: %s\n", i+1, cmd)
 	}
@@ -116,6 +147,8 @@ func (h *History) GetRecent(prefix string) string {
 		return ""
 	}
 
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	for i := len(h.commands) - 1; i >= 0; i-- {
 		if strings.HasPrefix(h.commands[i], prefix) {
 			return h.commands[i]
@@ -125,6 +158,18 @@ func (h *History) GetRecent(prefix string) string {
 	return ""
 }
 
+// Last returns up to n of the most recent commands, oldest first
+func (h *History) Last(n int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n > len(h.commands) {
+		n = len(h.commands)
+	}
+	result := make([]string, n)
+	copy(result, h.commands[len(h.commands)-n:])
+	return result
+}
+
 // GetHistoryPath returns the path to the history file
 func (h *History) GetHistoryPath() string {
 	return h.filePath
@@ -132,12 +177,150 @@ func (h *History) GetHistoryPath() string {
 
 // GetAll returns all commands in the history
 func (h *History) GetAll() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	// Return a copy of the history slice to prevent modification of the original
 	result := make([]string, len(h.commands))
 	copy(result, h.commands)
 	return result
 }
 
+// Search ranks the commands in history against query with BM25, leaving
+// out those last run before since when it is set
+func (h *History) Search(query string, since time.Time, limit int) []HistoryMatch {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.index.Search(query, since, limit)
+}
+
+// Undescribed returns up to n distinct commands that have no description
+// yet, most recently used first
+func (h *History) Undescribed(n int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.index.Undescribed(n)
+}
+
+// Describe adds descriptions of commands to the search index and saves them
+func (h *History) Describe(descriptions map[string]string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	saved := h.loadDescriptions()
+	for command, description := range descriptions {
+		description = strings.TrimSpace(description)
+		if description == "" {
+			continue
+		}
+		saved[command] = description
+		h.index.Describe(command, description)
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.descriptionsPath, data, 0600)
+}
+
+// IndexedCommands returns how many distinct commands can be searched
+func (h *History) IndexedCommands() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.index.Len()
+}
+
+// loadIndex builds the search index from the history, the recorded runs
+// and the saved descriptions. Commands from before runs were recorded are
+// indexed without a directory or time.
+func (h *History) loadIndex() {
+	inHistory := map[string]bool{}
+	for _, command := range h.commands {
+		inHistory[command] = true
+	}
+
+	recorded := map[string]bool{}
+	if file, err := os.Open(h.runsPath); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			h.runs++
+			var run historyRun
+			if json.Unmarshal(scanner.Bytes(), &run) != nil || !inHistory[run.Command] {
+				continue
+			}
+			recorded[run.Command] = true
+			h.index.Add(run.Command, run.Dir, run.Time)
+		}
+		file.Close()
+	}
+
+	for _, command := range h.commands {
+		if !recorded[command] {
+			h.index.Add(command, "", time.Time{})
+		}
+	}
+
+	for command, description := range h.loadDescriptions() {
+		h.index.Describe(command, description)
+	}
+}
+
+// loadDescriptions reads the saved command descriptions
+func (h *History) loadDescriptions() map[string]string {
+	descriptions := map[string]string{}
+	if data, err := os.ReadFile(h.descriptionsPath); err == nil {
+		_ = json.Unmarshal(data, &descriptions)
+	}
+	return descriptions
+}
+
+// recordRun adds a run of command to the index and appends it to the runs
+// file, which is compacted once it holds twice HISTORY_LIMIT runs
+func (h *History) recordRun(command string) {
+	run := historyRun{Command: command, Dir: displayDir(getCurrentDir()), Time: time.Now()}
+	h.index.Add(run.Command, run.Dir, run.Time)
+
+	line, err := json.Marshal(run)
+	if err != nil {
+		return
+	}
+	if h.runs >= 2*HISTORY_LIMIT {
+		h.compactRuns()
+	}
+
+	file, err := os.OpenFile(h.runsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err == nil {
+		h.runs++
+	}
+}
+
+// compactRuns keeps only the last HISTORY_LIMIT runs
+func (h *History) compactRuns() {
+	data, err := os.ReadFile(h.runsPath)
+	if err != nil {
+		return
+	}
+	lines := strings.SplitAfter(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > HISTORY_LIMIT {
+		lines = lines[len(lines)-HISTORY_LIMIT:]
+	}
+	kept := strings.TrimRight(strings.Join(lines, ""), "\n") + "\n"
+	if os.WriteFile(h.runsPath, []byte(kept), 0600) == nil {
+		h.runs = len(lines)
+	}
+}
+
+// displayDir shortens a directory under home to ~/...
+func displayDir(dir string) string {
+	if home, err := os.UserHomeDir(); err == nil && (dir == home || strings.HasPrefix(dir, home+string(filepath.Separator))) {
+		return "~" + dir[len(home):]
+	}
+	return dir
+}
+
 func GetHistoryFilePath() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -0,0 +1,332 @@
+package terminal
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BM25 parameters: how quickly repeated terms stop adding to a score, and
+// how much long commands are penalized
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights: a word in the command counts most, one in its description
+// almost as much, and the directory it ran in least
+const (
+	commandWeight     = 1.0
+	descriptionWeight = 0.8
+	dirWeight         = 0.5
+)
+
+// prefixPenalty scales the score of a query word that only matched as the
+// start of a longer word, as "kube" does "kubectl"
+const prefixPenalty = 0.6
+
+// HistoryMatch is one command found by a history search
+type HistoryMatch struct {
+	Command     string
+	Description string
+	Dirs        []string // where it was run, most recent first
+	Uses        int
+	Last        time.Time // zero for commands from before times were kept
+	Score       float64
+}
+
+// indexedCommand is one distinct command in the index
+type indexedCommand struct {
+	match  HistoryMatch
+	terms  map[string]float64 // term -> weighted frequency
+	length float64
+}
+
+// HistoryIndex is an inverted index over history for BM25 ranking. Each
+// distinct command is one document made of its words, the directories it
+// ran in and its description; running a command again updates it.
+type HistoryIndex struct {
+	docs        map[string]*indexedCommand
+	postings    map[string]map[string]float64 // term -> command -> weighted frequency
+	totalLength float64
+}
+
+// NewHistoryIndex creates an empty index
+func NewHistoryIndex() *HistoryIndex {
+	return &HistoryIndex{
+		docs:     map[string]*indexedCommand{},
+		postings: map[string]map[string]float64{},
+	}
+}
+
+// Add records a run of command in dir at t. Either may be unknown.
+func (x *HistoryIndex) Add(command, dir string, t time.Time) {
+	doc, ok := x.docs[command]
+	if !ok {
+		doc = &indexedCommand{match: HistoryMatch{Command: command}}
+		x.docs[command] = doc
+	}
+	doc.match.Uses++
+	if t.After(doc.match.Last) {
+		doc.match.Last = t
+	}
+	if dir != "" {
+		dirs := []string{dir}
+		for _, d := range doc.match.Dirs {
+			if d != dir {
+				dirs = append(dirs, d)
+			}
+		}
+		doc.match.Dirs = dirs
+	}
+	x.reindex(doc)
+}
+
+// Describe sets the description of a command already in the index
+func (x *HistoryIndex) Describe(command, description string) {
+	if doc, ok := x.docs[command]; ok && doc.match.Description != description {
+		doc.match.Description = description
+		x.reindex(doc)
+	}
+}
+
+// Undescribed returns up to n commands without a description, most
+// recently used first
+func (x *HistoryIndex) Undescribed(n int) []string {
+	var docs []*indexedCommand
+	for _, doc := range x.docs {
+		if doc.match.Description == "" {
+			docs = append(docs, doc)
+		}
+	}
+	sortByRecency(docs)
+
+	var commands []string
+	for _, doc := range docs {
+		if len(commands) == n {
+			break
+		}
+		commands = append(commands, doc.match.Command)
+	}
+	return commands
+}
+
+// Len returns the number of distinct commands indexed
+func (x *HistoryIndex) Len() int {
+	return len(x.docs)
+}
+
+// reindex replaces a command's postings after it changed
+func (x *HistoryIndex) reindex(doc *indexedCommand) {
+	for term := range doc.terms {
+		delete(x.postings[term], doc.match.Command)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	x.totalLength -= doc.length
+
+	doc.terms = map[string]float64{}
+	doc.length = 0
+	add := func(text string, weight float64) {
+		for _, term := range tokenize(text) {
+			doc.terms[term] += weight
+			doc.length += weight
+		}
+	}
+	add(doc.match.Command, commandWeight)
+	add(doc.match.Description, descriptionWeight)
+	for _, dir := range doc.match.Dirs {
+		add(dir, dirWeight)
+	}
+
+	for term, tf := range doc.terms {
+		if x.postings[term] == nil {
+			x.postings[term] = map[string]float64{}
+		}
+		x.postings[term][doc.match.Command] = tf
+	}
+	x.totalLength += doc.length
+}
+
+// Search ranks commands against the words of query and returns the best
+// limit of them. Commands last run before since are left out when since is
+// set. A query with no searchable words lists the most recent commands.
+func (x *HistoryIndex) Search(query string, since time.Time, limit int) []HistoryMatch {
+	if len(x.docs) == 0 {
+		return nil
+	}
+	n := float64(len(x.docs))
+	averageLength := x.totalLength / n
+
+	scores := map[string]float64{}
+	terms := tokenize(query)
+	for _, term := range uniqueTerms(terms) {
+		matched := x.postings[term]
+		weight := 1.0
+		if len(matched) == 0 && len(term) >= 3 {
+			// Fall back to longer words starting with the query word
+			matched = x.prefixPostings(term)
+			weight = prefixPenalty
+		}
+
+		idf := math.Log(1 + (n-float64(len(matched))+0.5)/(float64(len(matched))+0.5))
+		for command, tf := range matched {
+			length := x.docs[command].length
+			scores[command] += weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/averageLength))
+		}
+	}
+
+	var docs []*indexedCommand
+	for command, doc := range x.docs {
+		if !since.IsZero() && doc.match.Last.Before(since) {
+			continue
+		}
+		if len(terms) > 0 && scores[command] == 0 {
+			continue
+		}
+		doc.match.Score = scores[command]
+		docs = append(docs, doc)
+	}
+
+	// Recency breaks ties, and orders a query with no words
+	sortByRecency(docs)
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].match.Score > docs[j].match.Score })
+
+	matches := make([]HistoryMatch, 0, min(limit, len(docs)))
+	for _, doc := range docs[:min(limit, len(docs))] {
+		matches = append(matches, doc.match)
+	}
+	return matches
+}
+
+// prefixPostings merges the postings of every term starting with prefix
+func (x *HistoryIndex) prefixPostings(prefix string) map[string]float64 {
+	merged := map[string]float64{}
+	for term, postings := range x.postings {
+		if !strings.HasPrefix(term, prefix) {
+			continue
+		}
+		for command, tf := range postings {
+			merged[command] = max(merged[command], tf)
+		}
+	}
+	return merged
+}
+
+// sortByRecency orders commands by when they last ran, then by use
+func sortByRecency(docs []*indexedCommand) {
+	sort.Slice(docs, func(i, j int) bool {
+		a, b := docs[i].match, docs[j].match
+		if !a.Last.Equal(b.Last) {
+			return a.Last.After(b.Last)
+		}
+		if a.Uses != b.Uses {
+			return a.Uses > b.Uses
+		}
+		return a.Command < b.Command
+	})
+}
+
+// stopWords carry no meaning in a search of commands
+var stopWords = map[string]bool{
+	"the": true, "an": true, "and": true, "or": true, "of": true, "to": true, "in": true,
+	"on": true, "for": true, "with": true, "from": true, "that": true, "this": true,
+	"it": true, "is": true, "was": true, "my": true, "me": true, "command": true,
+	"where": true, "which": true, "ran": true, "used": true, "some": true,
+}
+
+// tokenize lowercases text and splits it into stemmed words, so "Mounts"
+// in a query finds "mount" in a command. Flags lose their dashes and paths
+// split at each separator.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) < 2 || stopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+// stem strips common English endings from longer words
+func stem(word string) string {
+	switch {
+	case len(word) > 5 && strings.HasSuffix(word, "ing"):
+		return word[:len(word)-3]
+	case len(word) > 4 && strings.HasSuffix(word, "ed"):
+		return word[:len(word)-2]
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 4 && (strings.HasSuffix(word, "sses") || strings.HasSuffix(word, "shes") || strings.HasSuffix(word, "xes")):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// timePhrases are the ways a query can say when a command ran
+var timePhrases = []struct {
+	pattern *regexp.Regexp
+	since   func(now time.Time, match []string) time.Time
+}{
+	{regexp.MustCompile(`(?i)\b(?:in the )?(?:last|past) (\d+) (day|week|month)s?\b`), func(now time.Time, match []string) time.Time {
+		count, _ := strconv.Atoi(match[1])
+		days := map[string]int{"day": 1, "week": 7, "month": 31}[strings.ToLower(match[2])]
+		return now.AddDate(0, 0, -count*days)
+	}},
+	{regexp.MustCompile(`(?i)\btoday\b`), func(now time.Time, _ []string) time.Time {
+		return startOfDay(now)
+	}},
+	{regexp.MustCompile(`(?i)\byesterday\b`), func(now time.Time, _ []string) time.Time {
+		return startOfDay(now).AddDate(0, 0, -1)
+	}},
+	{regexp.MustCompile(`(?i)\b(?:last|this|past) week\b`), func(now time.Time, _ []string) time.Time {
+		return now.AddDate(0, 0, -7)
+	}},
+	{regexp.MustCompile(`(?i)\b(?:last|this|past) month\b`), func(now time.Time, _ []string) time.Time {
+		return now.AddDate(0, -1, 0)
+	}},
+}
+
+// SplitTimePhrase takes a phrase such as "yesterday" or "last week" out of
+// a search query, returning the rest of the query and the earliest time it
+// allows. since is zero when the query names no time.
+func SplitTimePhrase(query string, now time.Time) (string, time.Time) {
+	for _, phrase := range timePhrases {
+		if match := phrase.pattern.FindStringSubmatch(query); match != nil {
+			rest := strings.Join(strings.Fields(strings.Replace(query, match[0], " ", 1)), " ")
+			return rest, phrase.since(now, match)
+		}
+	}
+	return query, time.Time{}
+}
+
+// startOfDay is midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// uniqueTerms drops repeated query terms
+func uniqueTerms(terms []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,178 @@
+package terminal
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// checkBookkeeping recomputes the postings and total length from the
+// documents and compares them with what the index kept up incrementally
+func checkBookkeeping(t *testing.T, x *HistoryIndex) {
+	t.Helper()
+
+	total := 0.0
+	postings := map[string]map[string]float64{}
+	for command, doc := range x.docs {
+		total += doc.length
+		for term, tf := range doc.terms {
+			if postings[term] == nil {
+				postings[term] = map[string]float64{}
+			}
+			postings[term][command] = tf
+		}
+	}
+
+	if math.Abs(total-x.totalLength) > 1e-9 {
+		t.Errorf("totalLength = %v, documents add up to %v", x.totalLength, total)
+	}
+	if len(postings) != len(x.postings) {
+		t.Errorf("index has %d terms, documents have %d", len(x.postings), len(postings))
+	}
+	for term, commands := range x.postings {
+		for command, tf := range commands {
+			if postings[term][command] != tf {
+				t.Errorf("posting %q -> %q = %v, document has %v", term, command, tf, postings[term][command])
+			}
+		}
+	}
+}
+
+// commands lists the commands of matches in order
+func commands(matches []HistoryMatch) []string {
+	var list []string
+	for _, m := range matches {
+		list = append(list, m.Command)
+	}
+	return list
+}
+
+func TestHistoryIndexSearch(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	x := NewHistoryIndex()
+
+	x.Add("docker compose up -d", "~/shop", now.Add(-72*time.Hour))
+	x.Add("kubectl get pods -n prod", "~/infra", now.Add(-48*time.Hour))
+	x.Add("mount /dev/sdb1 /mnt/usb", "", now.Add(-30*time.Hour))
+	x.Add("git log --oneline", "~/shop", now.Add(-2*time.Hour))
+	x.Add("docker compose up -d", "~/blog", now.Add(-1*time.Hour))
+	x.Add("df -h", "", time.Time{})
+	checkBookkeeping(t, x)
+
+	if x.Len() != 5 {
+		t.Fatalf("Len = %d, want 5 distinct commands", x.Len())
+	}
+
+	x.Describe("df -h", "show free disk space on mounted drives")
+	x.Describe("kubectl get pods -n prod", "list running production pods")
+	checkBookkeeping(t, x)
+
+	tests := []struct {
+		query string
+		since time.Time
+		want  []string
+	}{
+		// Words of the command count most
+		{"docker compose", time.Time{}, []string{"docker compose up -d"}},
+		// A description is searched, and stemming finds "mounted"
+		{"disk space", time.Time{}, []string{"df -h"}},
+		{"mounts", time.Time{}, []string{"mount /dev/sdb1 /mnt/usb", "df -h"}},
+		// Directories are searched too
+		{"blog", time.Time{}, []string{"docker compose up -d"}},
+		{"shop", time.Time{}, []string{"git log --oneline", "docker compose up -d"}},
+		// "kube" only starts a word, so it matches as a prefix
+		{"kube", time.Time{}, []string{"kubectl get pods -n prod"}},
+		{"production pods", time.Time{}, []string{"kubectl get pods -n prod"}},
+		{"nothing like this", time.Time{}, nil},
+		// Time limits leave out older runs and commands without a time
+		{"docker", now.Add(-90 * time.Minute), []string{"docker compose up -d"}},
+		{"mount", now.Add(-24 * time.Hour), nil},
+		// Without words, the most recent commands come first
+		{"", time.Time{}, []string{"docker compose up -d", "git log --oneline", "mount /dev/sdb1 /mnt/usb"}},
+	}
+
+	for _, tt := range tests {
+		if got := commands(x.Search(tt.query, tt.since, 3)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	match := x.Search("docker", time.Time{}, 1)[0]
+	if match.Uses != 2 || !slices.Equal(match.Dirs, []string{"~/blog", "~/shop"}) || !match.Last.Equal(now.Add(-time.Hour)) {
+		t.Errorf("docker match = %+v, want 2 uses, newest directory first", match)
+	}
+
+	if got := x.Undescribed(10); !slices.Equal(got, []string{"docker compose up -d", "git log --oneline", "mount /dev/sdb1 /mnt/usb"}) {
+		t.Errorf("Undescribed = %q", got)
+	}
+}
+
+func TestHistoryIndexReindex(t *testing.T) {
+	x := NewHistoryIndex()
+	x.Add("ls -la", "~/a", time.Time{})
+	x.Describe("ls -la", "list every file")
+	x.Describe("ls -la", "list all files including hidden ones")
+	x.Add("ls -la", "~/b", time.Time{})
+	x.Add("ls -la", "~/a", time.Time{})
+	x.Describe("missing", "not in the index")
+	checkBookkeeping(t, x)
+
+	// The old description's words are gone
+	if got := x.Search("every", time.Time{}, 5); len(got) != 0 {
+		t.Errorf("Search(every) = %q after the description changed", commands(got))
+	}
+	if got := x.Search("hidden", time.Time{}, 5); len(got) != 1 {
+		t.Errorf("Search(hidden) found %d commands, want 1", len(got))
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Mounts", []string{"mount"}},
+		{"mounted drives", []string{"mount", "drive"}},
+		{"running processes", []string{"runn", "process"}},
+		{"boxes and pushes", []string{"box", "push"}},
+		{"the command I used", nil},
+		{"git --oneline ~/src/app", []string{"git", "oneline", "src", "app"}},
+		{"class", []string{"class"}},
+		{"policies", []string{"policy"}},
+	}
+
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitTimePhrase(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	midnight := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query string
+		rest  string
+		since time.Time
+	}{
+		{"docker command from yesterday", "docker command from", midnight.AddDate(0, 0, -1)},
+		{"what did I mount today", "what did I mount", midnight},
+		{"kubectl in the last 3 days", "kubectl", now.AddDate(0, 0, -3)},
+		{"past 2 weeks ssh", "ssh", now.AddDate(0, 0, -14)},
+		{"Last 1 month backup", "backup", now.AddDate(0, 0, -31)},
+		{"rsync last week", "rsync", now.AddDate(0, 0, -7)},
+		{"this month deploy", "deploy", now.AddDate(0, -1, 0)},
+		{"ssh into the server", "ssh into the server", time.Time{}},
+		{"last command", "last command", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		rest, since := SplitTimePhrase(tt.query, now)
+		if rest != tt.rest || !since.Equal(tt.since) {
+			t.Errorf("SplitTimePhrase(%q) = %q, %v; want %q, %v", tt.query, rest, since, tt.rest, tt.since)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package terminal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
 	"github/0PrashantYadav0/GO-TERM/pkg/logger"
 	"github/0PrashantYadav0/GO-TERM/pkg/utils"
 	"io"
@@ -11,6 +13,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 )
@@ -25,6 +28,7 @@ type CommandLog struct {
 		CWD        string   `json:"cwd"`
 	} `json:"command"`
 	Output struct {
+		Stdout   string `json:"stdout,omitempty"`
 		Stderr   string `json:"stderr"`
 		ExitCode int    `json:"exitCode"`
 		Error    string `json:"error,omitempty"`
@@ -36,39 +40,58 @@ type CommandLog struct {
 	} `json:"metadata"`
 }
 
-// ExecuteCommand executes a shell command and handles the result
-func ExecuteCommand(input string) {
+// ExecuteCommand executes a shell command and returns its log entry, or nil
+// if there was nothing to run
+func ExecuteCommand(input string) *CommandLog {
 	logEntry := initCommandLog(input)
 
-	parts := strings.Fields(input)
+	parts, err := SplitArgs(input)
+	if err != nil {
+		// Match the shell's exit status for a syntax error
+		logEntry.Output.Error = err.Error()
+		logEntry.Output.ExitCode = 2
+		fmt.Println("Error parsing command:", err)
+		saveCommandLog(logEntry)
+		return logEntry
+	}
 	if len(parts) == 0 {
-		return
+		return nil
 	}
 
 	cmd := exec.Command(parts[0], parts[1:]...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 
-	// Capture stderr separately
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		fmt.Println("Error setting up stderr pipe:", err)
-		return
+	// Keep a bounded copy of the output for hx when asked to. The command
+	// then writes to a pipe and no longer sees a terminal, so full-screen
+	// programs are always left alone.
+	settings := config.GetConfig().Capture
+	limit := settings.Limit
+	if limit <= 0 {
+		limit = defaultCaptureLimit
 	}
 
+	var stdout *BoundedBuffer
+	if settings.Enabled && !slices.Contains(settings.Passthrough, filepath.Base(parts[0])) {
+		stdout = NewBoundedBuffer(limit)
+		cmd.Stdout = io.MultiWriter(os.Stdout, stdout)
+	}
+
+	// Capture stderr separately
+	stderr := NewBoundedBuffer(limit)
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+
 	err = cmd.Start()
 	if err != nil {
 		logEntry.Output.Error = err.Error()
+		// Match the shell's exit status for a missing command
 		logEntry.Output.ExitCode = 1
+		if errors.Is(err, exec.ErrNotFound) {
+			logEntry.Output.ExitCode = 127
+		}
 		fmt.Println("Error starting command:", err)
 		saveCommandLog(logEntry)
-		return
-	}
-
-	// Read stderr
-	stderrBytes, _ := io.ReadAll(stderrPipe)
-	if len(stderrBytes) > 0 {
-		logEntry.Output.Stderr = string(stderrBytes)
+		return logEntry
 	}
 
 	err = cmd.Wait()
@@ -81,10 +104,17 @@ func ExecuteCommand(input string) {
 		}
 	}
 
+	logEntry.Output.Stderr = stderr.String()
+	if stdout != nil {
+		logEntry.Output.Stdout = stdout.String()
+	}
+
 	// Save to error log file if there was an error
 	if logEntry.Output.ExitCode != 0 || logEntry.Output.Stderr != "" {
 		saveCommandLog(logEntry)
 	}
+
+	return logEntry
 }
 
 // SafeExecuteCommand executes a command with better error recovery
@@ -244,7 +274,10 @@ func min(a, b int) int {
 }
 
 func initCommandLog(command string) *CommandLog {
-	parts := strings.Fields(command)
+	parts, err := SplitArgs(command)
+	if err != nil {
+		parts = strings.Fields(command)
+	}
 
 	log := &CommandLog{
 		ID:        generateID(),
@@ -271,7 +304,12 @@ func generateID() string {
 	return fmt.Sprintf("cmd_%d_%s", time.Now().Unix(), utils.RandomString(8))
 }
 
+// saveCommandLog appends a failed command to ~/.goterm_error for hm. Its
+// stdout stays in memory for hx and is never written to disk.
 func saveCommandLog(log *CommandLog) {
+	saved := *log
+	saved.Output.Stdout = ""
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Println("Error getting home directory:", err)
@@ -287,7 +325,7 @@ func saveCommandLog(log *CommandLog) {
 	}
 
 	// Add new log
-	logs = append(logs, *log)
+	logs = append(logs, saved)
 
 	// Keep only the last 10 logs
 	if len(logs) > 10 {
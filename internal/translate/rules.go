@@ -0,0 +1,217 @@
+package translate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rule rewrites a pattern for a target. Rules with a rewrite func get the
+// submatches of each match; the others expand replace like
+// regexp.ReplaceAllString. A cmdlet rule that can't carry every flag over
+// names what it dropped with lost.
+type rule struct {
+	pattern *regexp.Regexp
+	replace string
+	rewrite func(match []string) string
+	lost    func(match []string) string
+	note    string
+}
+
+// gap is a pattern the rules know they can't translate
+type gap struct {
+	pattern *regexp.Regexp
+	note    string
+}
+
+// start matches where a command name can begin: the start of the line or
+// after an operator
+const start = `(^|[|;&(]\s*)`
+
+// dateUnits maps BSD date -v units to GNU date -d words
+var dateUnits = map[string]string{
+	"y": "year", "m": "month", "w": "week", "d": "day", "H": "hour", "M": "minute", "S": "second",
+}
+
+// rules are applied in order, so a later rule sees the output of earlier ones
+var rules = map[string][]rule{
+	Linux: {
+		{pattern: regexp.MustCompile(`\bsed\s+-i\s*(''|"")\s*`), replace: "sed -i ", note: "GNU sed -i takes no backup suffix argument"},
+		{pattern: regexp.MustCompile(`\bsed\s+-i\s+(?:'(\.[^']+)'|"(\.[^"]+)"|(\.[\w.-]+)\s)`), rewrite: func(m []string) string {
+			if m[3] != "" {
+				return "sed -i" + m[3] + " " // the match took the blank after the suffix
+			}
+			return "sed -i" + m[1] + m[2]
+		}, note: "GNU sed wants the backup suffix attached to -i"},
+		{pattern: regexp.MustCompile(`\bstat\s+-f\s*('[^']*%[^']*'|"[^"]*%[^"]*"|\S*%\S*)`), rewrite: func(m []string) string {
+			return "stat -c " + convertStatFormat(m[1], false)
+		}, note: "GNU stat takes its format with -c, and names the fields differently (%z size becomes %s)"},
+		{pattern: regexp.MustCompile(`\bdate\s+-v\s*([+-])(\d+)([ymwdHMS])\b`), rewrite: func(m []string) string {
+			return "date -d '" + m[1] + m[2] + " " + dateUnits[m[3]] + "'"
+		}, note: "GNU date adjusts dates with -d and a relative phrase instead of -v"},
+		{pattern: regexp.MustCompile(`\bbase64\s+-D\b`), replace: "base64 -d", note: "GNU base64 decodes with -d"},
+		{pattern: regexp.MustCompile(start + `md5\s+(-r\s+)?`), replace: "${1}md5sum ", note: "Linux has md5sum instead of md5"},
+		{pattern: regexp.MustCompile(`\bshasum\s+-a\s*(1|224|256|384|512)\b`), replace: "sha${1}sum", note: "Linux has a shaNsum tool per algorithm"},
+		{pattern: regexp.MustCompile(`\bsysctl\s+-n\s+hw\.(ncpu|logicalcpu)\b`), replace: "nproc", note: "nproc counts CPUs on Linux"},
+		{pattern: regexp.MustCompile(`\btail\s+-r\b`), replace: "tac", note: "GNU tail can't reverse; tac does"},
+		{pattern: regexp.MustCompile(`\bls\s+-G\b`), replace: "ls --color=auto", note: "-G means --no-group to GNU ls; colours are --color"},
+		{pattern: regexp.MustCompile(`\bfind\s+-E\s+(\S+)`), replace: "find $1 -regextype posix-extended", note: "GNU find selects extended regexes with -regextype"},
+		{pattern: regexp.MustCompile(start + `open\s`), replace: "${1}xdg-open ", note: "xdg-open opens files with the default application"},
+		{pattern: regexp.MustCompile(start + `pbcopy\b`), replace: "${1}xclip -selection clipboard", note: "xclip reaches the clipboard on X11 (wl-copy on Wayland)"},
+		{pattern: regexp.MustCompile(start + `pbpaste\b`), replace: "${1}xclip -selection clipboard -o", note: "xclip reaches the clipboard on X11 (wl-paste on Wayland)"},
+		{pattern: regexp.MustCompile(start + `sw_vers\b`), replace: "${1}cat /etc/os-release", note: "/etc/os-release names the Linux distribution and version"},
+	},
+
+	MacOS: {
+		{pattern: regexp.MustCompile(`\bsed\s+(?:-i|--in-place)(\S*)(\s+)(''|"")?`), rewrite: func(m []string) string {
+			if m[3] != "" {
+				return m[0] // already BSD style
+			}
+			suffix := strings.TrimPrefix(m[1], "=")
+			return "sed -i '" + suffix + "'" + m[2]
+		}, note: "BSD sed -i requires a backup suffix argument; '' means none"},
+		{pattern: regexp.MustCompile(`\bsed\s+-r\b`), replace: "sed -E", note: "BSD sed enables extended regexes with -E"},
+		{pattern: regexp.MustCompile(`\bstat\s+(?:-c\s*|--format=|--printf=)('[^']*%[^']*'|"[^"]*%[^"]*"|\S*%\S*)`), rewrite: func(m []string) string {
+			return "stat -f " + convertStatFormat(m[1], true)
+		}, note: "BSD stat takes its format with -f, and names the fields differently (%s size becomes %z)"},
+		{pattern: regexp.MustCompile(`\bdate\s+(?:-d|--date)[\s=]*(['"])(?:([+-]?)(\d+)\s+(year|month|week|day|hour|minute|second)s?(\s+ago)?)(['"])`), rewrite: func(m []string) string {
+			sign := m[2]
+			if m[5] != "" {
+				sign = "-"
+			} else if sign == "" {
+				sign = "+"
+			}
+			unit := map[string]string{"year": "y", "month": "m", "week": "w", "day": "d", "hour": "H", "minute": "M", "second": "S"}[m[4]]
+			return "date -v" + sign + m[3] + unit
+		}, note: "BSD date adjusts dates with -v instead of -d"},
+		{pattern: regexp.MustCompile(start + `md5sum\b`), replace: "${1}md5 -r", note: "macOS has md5; -r prints hash then name like md5sum"},
+		{pattern: regexp.MustCompile(`\bsha(1|224|256|384|512)sum\b`), replace: "shasum -a $1", note: "macOS has one shasum tool for every algorithm"},
+		{pattern: regexp.MustCompile(start + `nproc\b`), replace: "${1}sysctl -n hw.ncpu", note: "macOS reports the CPU count through sysctl"},
+		{pattern: regexp.MustCompile(start + `tac\b`), replace: "${1}tail -r", note: "macOS has no tac; BSD tail -r reverses"},
+		{pattern: regexp.MustCompile(`\bls\s+--color(=\w+)?`), replace: "ls -G", note: "BSD ls turns on colours with -G"},
+		{pattern: regexp.MustCompile(`\bxargs\s+(?:-r|--no-run-if-empty)\s+`), replace: "xargs ", note: "BSD xargs already skips empty input"},
+		{pattern: regexp.MustCompile(`\breadlink\s+-f\b`), replace: "realpath", note: "BSD readlink has no -f; realpath resolves the full path"},
+		{pattern: regexp.MustCompile(`\bdu\s+--max-depth=(\d+)`), replace: "du -d $1", note: "BSD du limits depth with -d"},
+		{pattern: regexp.MustCompile(`\bfind\s+(\S+)\s+-regextype\s+(?:posix-)?extended`), replace: "find -E $1", note: "BSD find selects extended regexes with -E"},
+		{pattern: regexp.MustCompile(start + `xdg-open\b`), replace: "${1}open", note: "open uses the default application on macOS"},
+		{pattern: regexp.MustCompile(start + `(?:xclip\s+-sel(?:ection)?\s+c(?:lipboard)?\s+-o|xsel\s+(?:-b\s+-o|-ob|--clipboard\s+--output)|wl-paste)\b`), replace: "${1}pbpaste", note: "pbpaste reads the macOS clipboard"},
+		{pattern: regexp.MustCompile(start + `(?:xclip\s+-sel(?:ection)?\s+c(?:lipboard)?|xsel\s+(?:-b|--clipboard)(?:\s+-i)?|wl-copy)\b`), replace: "${1}pbcopy", note: "pbcopy writes the macOS clipboard"},
+		{pattern: regexp.MustCompile(`\bcat\s+/etc/os-release\b`), replace: "sw_vers", note: "sw_vers prints the macOS version"},
+	},
+
+	Fish: {
+		{pattern: regexp.MustCompile(start + `export\s+([A-Za-z_]\w*)=("[^"]*"|'[^']*'|\S*)`), replace: "${1}set -gx $2 $3", note: "fish sets environment variables with set -gx"},
+		{pattern: regexp.MustCompile(start + `unset\s+([A-Za-z_]\w*)`), replace: "${1}set -e $2", note: "fish erases variables with set -e"},
+		{pattern: regexp.MustCompile(start + `((?:[A-Za-z_]\w*=\S*\s+)+)([^\s=]+(?:\s|$))`), replace: "${1}env $2$3", note: "fish before 3.1 needs env to set a variable for one command"},
+		{pattern: regexp.MustCompile(`\$\?`), replace: "$$status", note: "fish keeps the last exit status in $status"},
+		{pattern: regexp.MustCompile("`([^`]*)`"), replace: "($1)", note: "fish substitutes commands with (...)"},
+		{pattern: regexp.MustCompile(`\$\(([^()]*)\)`), replace: "($1)", note: "fish substitutes commands with (...); $(...) needs fish 3.4"},
+		{pattern: regexp.MustCompile(start + `source\s+~/\.bashrc\b`), replace: "${1}source ~/.config/fish/config.fish", note: "fish reads ~/.config/fish/config.fish"},
+	},
+
+	PowerShell: {
+		// Variables first; each command is then rewritten as a whole
+		{pattern: envVariable, rewrite: func(m []string) string { return envVariables(m[0]) }, note: "PowerShell reads environment variables as $env:NAME"},
+	},
+}
+
+// cmdlets rewrite one simple command for PowerShell. The first whose
+// pattern matches the whole command is used.
+var cmdlets = []rule{
+	{pattern: regexp.MustCompile(`^ls((?:\s+-\w+)*)(?:\s+([^-\s]\S*))?$`), rewrite: func(m []string) string {
+		cmd := "Get-ChildItem"
+		if strings.ContainsAny(m[1], "aA") {
+			cmd += " -Force"
+		}
+		if strings.Contains(m[1], "R") {
+			cmd += " -Recurse"
+		}
+		if m[2] != "" {
+			cmd += " " + m[2]
+		}
+		return cmd
+	}, lost: func(m []string) string {
+		// Get-ChildItem always lists one entry per line with its details
+		if dropped := strings.Trim(strings.Map(func(r rune) rune {
+			if strings.ContainsRune("laAR1- \t", r) {
+				return -1
+			}
+			return r
+		}, m[1]), " "); dropped != "" {
+			return "ls -" + dropped + " has no Get-ChildItem switch; sort or filter its output with Sort-Object or Where-Object"
+		}
+		return ""
+	}, note: "ls is Get-ChildItem; -Force includes hidden files and -Recurse descends"},
+	{pattern: regexp.MustCompile(`^cat\s+(.+)$`), replace: "Get-Content $1", note: "cat is Get-Content"},
+	{pattern: regexp.MustCompile(`^rm\s+-(?:rf|fr|r|R|Rf)\s+(.+)$`), replace: "Remove-Item -Recurse -Force $1", note: "rm -r is Remove-Item -Recurse"},
+	{pattern: regexp.MustCompile(`^rm\s+-f\s+(.+)$`), replace: "Remove-Item $1 -ErrorAction SilentlyContinue", note: "rm -f is Remove-Item, ignoring missing files"},
+	{pattern: regexp.MustCompile(`^rm\s+(.+)$`), replace: "Remove-Item $1", note: "rm is Remove-Item"},
+	{pattern: regexp.MustCompile(`^mkdir\s+-p\s+(.+)$`), replace: "New-Item -ItemType Directory -Force -Path $1", note: "-Force creates parents and tolerates existing directories"},
+	{pattern: regexp.MustCompile(`^mkdir\s+(.+)$`), replace: "New-Item -ItemType Directory -Path $1", note: "mkdir is New-Item -ItemType Directory"},
+	{pattern: regexp.MustCompile(`^cp\s+-[rR]\s+(\S+)\s+(\S+)$`), replace: "Copy-Item -Recurse $1 $2", note: "cp -r is Copy-Item -Recurse"},
+	{pattern: regexp.MustCompile(`^cp\s+(\S+)\s+(\S+)$`), replace: "Copy-Item $1 $2", note: "cp is Copy-Item"},
+	{pattern: regexp.MustCompile(`^mv\s+(\S+)\s+(\S+)$`), replace: "Move-Item $1 $2", note: "mv is Move-Item"},
+	{pattern: regexp.MustCompile(`^pwd$`), replace: "Get-Location", note: "pwd is Get-Location"},
+	{pattern: regexp.MustCompile(`^cd\s+(.+)$`), replace: "Set-Location $1", note: "cd is Set-Location"},
+	{pattern: regexp.MustCompile(`^which\s+(\S+)$`), replace: "Get-Command $1", note: "which is Get-Command"},
+	{pattern: regexp.MustCompile(`^echo\s+(.*)$`), replace: "Write-Output $1", note: "echo is Write-Output"},
+	{pattern: regexp.MustCompile(`^export\s+([A-Za-z_]\w*)=(?:"([^"]*)"|'([^']*)'|(\S*))$`), replace: `$$env:$1 = "$2$3$4"`, note: "environment variables are set through $env:"},
+	{pattern: regexp.MustCompile(`^grep\s+(-i\s+)?("[^"]*"|'[^']*'|\S+)(?:\s+(\S+))?$`), rewrite: func(m []string) string {
+		cmd := "Select-String -Pattern " + m[2]
+		if m[3] != "" {
+			cmd += " -Path " + m[3]
+		}
+		if m[1] == "" {
+			// Select-String ignores case unless told otherwise
+			cmd += " -CaseSensitive"
+		}
+		return cmd
+	}, note: "grep is Select-String, which ignores case by default"},
+	{pattern: regexp.MustCompile(`^head\s+-n?\s*(\d+)\s+(\S+)$`), replace: "Get-Content $2 -TotalCount $1", note: "head is Get-Content -TotalCount"},
+	{pattern: regexp.MustCompile(`^tail\s+-f\s+(\S+)$`), replace: "Get-Content $1 -Wait", note: "tail -f is Get-Content -Wait"},
+	{pattern: regexp.MustCompile(`^tail\s+-n?\s*(\d+)\s+(\S+)$`), replace: "Get-Content $2 -Tail $1", note: "tail is Get-Content -Tail"},
+	{pattern: regexp.MustCompile(`^head\s+-n?\s*(\d+)$`), replace: "Select-Object -First $1", note: "in a pipeline, head is Select-Object -First"},
+	{pattern: regexp.MustCompile(`^tail\s+-n?\s*(\d+)$`), replace: "Select-Object -Last $1", note: "in a pipeline, tail is Select-Object -Last"},
+	{pattern: regexp.MustCompile(`^wc\s+-l$`), replace: "Measure-Object -Line", note: "wc -l is Measure-Object -Line"},
+	{pattern: regexp.MustCompile(`^sort$`), replace: "Sort-Object", note: "sort is Sort-Object"},
+	{pattern: regexp.MustCompile(`^uniq$`), replace: "Get-Unique", note: "uniq is Get-Unique"},
+	{pattern: regexp.MustCompile(`^find\s+(\S+)\s+-type\s+f\s+-name\s+(\S+)$`), replace: "Get-ChildItem -Path $1 -Recurse -File -Filter $2", note: "find is Get-ChildItem -Recurse"},
+	{pattern: regexp.MustCompile(`^find\s+(\S+)\s+-name\s+(\S+)$`), replace: "Get-ChildItem -Path $1 -Recurse -Filter $2", note: "find is Get-ChildItem -Recurse"},
+	{pattern: regexp.MustCompile(`^curl\s+(?:-[sSLf]+\s+)*-o\s+(\S+)\s+(\S+)$`), replace: "Invoke-WebRequest -Uri $2 -OutFile $1", note: "curl -o is Invoke-WebRequest -OutFile"},
+	{pattern: regexp.MustCompile(`^curl\s+(?:-[sSLf]+\s+)*(\S+)$`), replace: "(Invoke-WebRequest -Uri $1).Content", note: "curl is Invoke-WebRequest"},
+	{pattern: regexp.MustCompile(`^ps(?:\s+aux|\s+-ef)?$`), replace: "Get-Process", note: "ps is Get-Process"},
+	{pattern: regexp.MustCompile(`^kill\s+-9\s+(\d+)$`), replace: "Stop-Process -Id $1 -Force", note: "kill -9 is Stop-Process -Force"},
+	{pattern: regexp.MustCompile(`^kill\s+(\d+)$`), replace: "Stop-Process -Id $1", note: "kill is Stop-Process"},
+	{pattern: regexp.MustCompile(`^env$`), replace: "Get-ChildItem Env:", note: "the Env: drive lists environment variables"},
+	{pattern: regexp.MustCompile(`^clear$`), replace: "Clear-Host", note: "clear is Clear-Host"},
+}
+
+// gaps are checked after the rules have run
+var gaps = map[string][]gap{
+	Linux: {
+		{regexp.MustCompile(`\blaunchctl\b`), "launchctl manages macOS services; Linux uses systemctl"},
+		{regexp.MustCompile(`\bdiskutil\b`), "diskutil is macOS only; Linux uses lsblk, mount and parted"},
+		{regexp.MustCompile(`\bdefaults\s+(read|write)\b`), "defaults edits macOS preferences and has no Linux equivalent"},
+		{regexp.MustCompile(`\bmdfind\b`), "mdfind searches the Spotlight index; use locate or find"},
+		{regexp.MustCompile(`\bstat\s+-f\s*[^%\s]`), "GNU stat -f shows file system status, not a formatted file"},
+	},
+	MacOS: {
+		{regexp.MustCompile(`\bgrep\s+(-\w*P|--perl-regexp)`), "BSD grep has no -P; rewrite with -E or install GNU grep as ggrep"},
+		{regexp.MustCompile(start + `free\b`), "macOS has no free; vm_stat reports memory in pages"},
+		{regexp.MustCompile(start + `ip\s+(a|addr|address|r|route|link)\b`), "macOS has no ip; use ifconfig and netstat -rn"},
+		{regexp.MustCompile(`\bsystemctl\b`), "macOS manages services with launchctl or brew services"},
+		{regexp.MustCompile(start + `(apt|apt-get|dnf|yum)\b`), "macOS installs packages with Homebrew"},
+		{regexp.MustCompile(`\bdate\s+(-d|--date)\b`), "BSD date has no -d; only simple relative dates are translated"},
+	},
+	Fish: {
+		{regexp.MustCompile(`\[\[`), "fish has no [[ ]]; use test or string match"},
+		{regexp.MustCompile(`\b(then|fi|do|done|esac)\b`), "bash if, for, while and case blocks must be rewritten in fish syntax"},
+		{regexp.MustCompile(`<<`), "fish has no here-documents"},
+		{regexp.MustCompile(`\$\{`), "fish has no ${...} parameter expansion"},
+		{regexp.MustCompile(`\(\)\s*\{|\bfunction\s+\w+\s*\{`), "bash functions must be rewritten with function ... end"},
+		{regexp.MustCompile(`\$\(`), "nested $(...) substitution wasn't translated"},
+	},
+	PowerShell: {
+		{regexp.MustCompile(`\bsudo\b`), "PowerShell has no sudo; run the shell as Administrator"},
+		{regexp.MustCompile(`[^2&]>\s*/dev/null|2>\s*/dev/null`), "/dev/null is $null in PowerShell"},
+	},
+}
@@ -0,0 +1,234 @@
+package translate
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Targets a command can be translated to
+const (
+	Linux      = "linux"
+	MacOS      = "macos"
+	PowerShell = "powershell"
+	Fish       = "fish"
+)
+
+// Targets lists the supported targets
+var Targets = []string{Linux, MacOS, PowerShell, Fish}
+
+// descriptions tell the model what each target runs
+var descriptions = map[string]string{
+	Linux:      "Linux with GNU coreutils, GNU sed, GNU date and bash",
+	MacOS:      "macOS with the BSD userland (BSD sed, stat, date, xargs) and zsh",
+	PowerShell: "PowerShell 7 on Windows, using cmdlets rather than Unix tools",
+	Fish:       "the fish shell 3 on a Unix system",
+}
+
+// Change is one rewrite made by a rule
+type Change struct {
+	Before string
+	After  string
+	Note   string
+}
+
+// Result is what the local rules made of a command
+type Result struct {
+	Command string
+	Changes []Change
+	Gaps    []string // parts the rules know they can't translate
+
+	// Complete is set when the rules covered the whole command. A command
+	// no rule touched isn't complete, as it may differ in ways the rules
+	// don't know.
+	Complete bool
+}
+
+// Valid reports whether target is supported
+func Valid(target string) bool {
+	_, ok := descriptions[target]
+	return ok
+}
+
+// Describe says what a target runs, for the model
+func Describe(target string) string {
+	return descriptions[target]
+}
+
+// Current returns the target matching the platform GO-TERM runs on
+func Current() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return MacOS
+	case "windows":
+		return PowerShell
+	default:
+		return Linux
+	}
+}
+
+// Translate rewrites command for target using the rule tables
+func Translate(command, target string) (Result, error) {
+	if !Valid(target) {
+		return Result{}, fmt.Errorf("unknown target %q (use %s)", target, strings.Join(Targets, ", "))
+	}
+
+	result := Result{Command: command}
+	for _, r := range rules[target] {
+		result.Command = r.apply(result.Command, &result.Changes)
+	}
+	for _, g := range gaps[target] {
+		if g.pattern.MatchString(result.Command) {
+			result.Gaps = append(result.Gaps, g.note)
+		}
+	}
+
+	if target == PowerShell {
+		result.Command = translateSegments(result.Command, &result)
+	}
+
+	result.Complete = len(result.Gaps) == 0 && len(result.Changes) > 0
+	return result, nil
+}
+
+// apply runs a rule over command, recording what it changed
+func (r rule) apply(command string, changes *[]Change) string {
+	return r.pattern.ReplaceAllStringFunc(command, func(match string) string {
+		var replaced string
+		if r.rewrite != nil {
+			replaced = r.rewrite(r.pattern.FindStringSubmatch(match))
+		} else {
+			replaced = r.pattern.ReplaceAllString(match, r.replace)
+		}
+		if replaced != match {
+			*changes = append(*changes, Change{Before: strings.TrimSpace(match), After: strings.TrimSpace(replaced), Note: r.note})
+		}
+		return replaced
+	})
+}
+
+// translateSegments rewrites each command of a pipeline or list with the
+// PowerShell table, leaving a gap for any the table doesn't cover
+func translateSegments(command string, result *Result) string {
+	var b strings.Builder
+	for _, part := range splitSegments(command) {
+		if part.operator != "" {
+			if part.operator == "&&" || part.operator == "||" {
+				result.Changes = append(result.Changes, Change{Before: part.operator, After: part.operator, Note: part.operator + " needs PowerShell 7 or later"})
+			}
+			b.WriteString(" " + part.operator + " ")
+			continue
+		}
+
+		segment := strings.TrimSpace(part.text)
+		translated := false
+		for _, r := range cmdlets {
+			if !r.pattern.MatchString(segment) {
+				continue
+			}
+			if r.lost != nil {
+				if note := r.lost(r.pattern.FindStringSubmatch(segment)); note != "" {
+					result.Gaps = append(result.Gaps, note)
+				}
+			}
+			var changes []Change
+			segment = r.apply(segment, &changes)
+			result.Changes = append(result.Changes, changes...)
+			translated = true
+			break
+		}
+		if !translated {
+			name, _, _ := strings.Cut(segment, " ")
+			result.Gaps = append(result.Gaps, "no PowerShell rule for "+name)
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// segment is a simple command, or the operator between two
+type segment struct {
+	text     string
+	operator string
+}
+
+// splitSegments splits a command at unquoted |, ||, && and ;
+func splitSegments(command string) []segment {
+	var parts []segment
+	var current strings.Builder
+	var quote byte
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\' && i+1 < len(command):
+			current.WriteString(command[i : i+2])
+			i++
+			continue
+		case c == '|' || c == ';' || (c == '&' && i+1 < len(command) && command[i+1] == '&'):
+			operator := string(c)
+			if i+1 < len(command) && command[i+1] == c && c != ';' {
+				operator += string(c)
+				i++
+			}
+			parts = append(parts, segment{text: current.String()}, segment{operator: operator})
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+
+	return append(parts, segment{text: current.String()})
+}
+
+// envVariable matches an upper-case shell variable such as $HOME
+var envVariable = regexp.MustCompile(`\$([A-Z_][A-Z0-9_]*)\b`)
+
+// powerShellVariables are automatic PowerShell variables with the same
+// meaning as the shell's
+var powerShellVariables = map[string]bool{"HOME": true, "PWD": true}
+
+// envVariables rewrites $NAME as PowerShell's $env:NAME
+func envVariables(text string) string {
+	return envVariable.ReplaceAllStringFunc(text, func(match string) string {
+		if powerShellVariables[match[1:]] {
+			return match
+		}
+		return "$env:" + match[1:]
+	})
+}
+
+// statFormats maps BSD stat -f format letters to GNU stat -c ones
+var statFormats = map[string]string{
+	"%z": "%s", "%m": "%Y", "%a": "%X", "%c": "%Z", "%N": "%n", "%Sp": "%A",
+	"%Lp": "%a", "%Su": "%U", "%Sg": "%G", "%u": "%u", "%g": "%g", "%i": "%i",
+	"%l": "%h", "%HT": "%F", "%d": "%d", "%b": "%b",
+}
+
+// statFormat matches one stat format directive, BSD or GNU
+var statFormat = regexp.MustCompile(`%(?:S[pugam]|Lp|HT|[a-zA-Z])`)
+
+// convertStatFormat rewrites the directives of a stat format using mapping,
+// or its inverse when reverse is set
+func convertStatFormat(format string, reverse bool) string {
+	mapping := statFormats
+	if reverse {
+		mapping = map[string]string{}
+		for bsd, gnu := range statFormats {
+			mapping[gnu] = bsd
+		}
+	}
+	return statFormat.ReplaceAllStringFunc(format, func(directive string) string {
+		if converted, ok := mapping[directive]; ok {
+			return converted
+		}
+		return directive
+	})
+}
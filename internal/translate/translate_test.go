@@ -0,0 +1,126 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		target  string
+		command string
+		want    string
+		gap     string // part of a gap note that must be reported
+	}{
+		// macOS to Linux
+		{Linux, `sed -i '' 's/a/b/' f.txt`, `sed -i 's/a/b/' f.txt`, ""},
+		{Linux, `sed -i "" -e 's/a/b/' f.txt`, `sed -i -e 's/a/b/' f.txt`, ""},
+		{Linux, `sed -i '.bak' 's/a/b/' f.txt`, `sed -i.bak 's/a/b/' f.txt`, ""},
+		{Linux, `sed -i .orig 's/a/b/' f.txt`, `sed -i.orig 's/a/b/' f.txt`, ""},
+		{Linux, `stat -f '%z %N' f.txt`, `stat -c '%s %n' f.txt`, ""},
+		{Linux, `stat -f %Sp f.txt`, `stat -c %A f.txt`, ""},
+		{Linux, `stat -f /`, `stat -f /`, "file system status"},
+		{Linux, `date -v-1d +%F`, `date -d '-1 day' +%F`, ""},
+		{Linux, `base64 -D < in`, `base64 -d < in`, ""},
+		{Linux, `md5 -r f.txt | sort`, `md5sum f.txt | sort`, ""},
+		{Linux, `shasum -a 256 f.txt`, `sha256sum f.txt`, ""},
+		{Linux, `sysctl -n hw.ncpu`, `nproc`, ""},
+		{Linux, `tail -r log.txt`, `tac log.txt`, ""},
+		{Linux, `ls -G`, `ls --color=auto`, ""},
+		{Linux, `find -E . -regex '.*\.go'`, `find . -regextype posix-extended -regex '.*\.go'`, ""},
+		{Linux, `cat a | pbcopy`, `cat a | xclip -selection clipboard`, ""},
+		{Linux, `pbpaste > a`, `xclip -selection clipboard -o > a`, ""},
+		{Linux, `open .`, `xdg-open .`, ""},
+		{Linux, `launchctl list`, `launchctl list`, "systemctl"},
+
+		// Linux to macOS
+		{MacOS, `sed -i 's/a/b/' f.txt`, `sed -i '' 's/a/b/' f.txt`, ""},
+		{MacOS, `sed -i.bak 's/a/b/' f.txt`, `sed -i '.bak' 's/a/b/' f.txt`, ""},
+		{MacOS, `sed --in-place=.bak 's/a/b/' f.txt`, `sed -i '.bak' 's/a/b/' f.txt`, ""},
+		{MacOS, `sed -i '' 's/a/b/' f.txt`, `sed -i '' 's/a/b/' f.txt`, ""},
+		{MacOS, `sed -r 's/(a)+/b/' f.txt`, `sed -E 's/(a)+/b/' f.txt`, ""},
+		{MacOS, `stat -c '%s %n' f.txt`, `stat -f '%z %N' f.txt`, ""},
+		{MacOS, `stat --format=%A f.txt`, `stat -f %Sp f.txt`, ""},
+		{MacOS, `stat -c %a f.txt`, `stat -f %Lp f.txt`, ""},
+		{MacOS, `date -d '2 days ago' +%F`, `date -v-2d +%F`, ""},
+		{MacOS, `date -d "+3 hours"`, `date -v+3H`, ""},
+		{MacOS, `date -d 'next friday'`, `date -d 'next friday'`, "BSD date has no -d"},
+		{MacOS, `md5sum f.txt`, `md5 -r f.txt`, ""},
+		{MacOS, `sha256sum f.txt`, `shasum -a 256 f.txt`, ""},
+		{MacOS, `nproc`, `sysctl -n hw.ncpu`, ""},
+		{MacOS, `tac log.txt`, `tail -r log.txt`, ""},
+		{MacOS, `ls --color=auto`, `ls -G`, ""},
+		{MacOS, `find . -name '*.tmp' | xargs -r rm`, `find . -name '*.tmp' | xargs rm`, ""},
+		{MacOS, `readlink -f link`, `realpath link`, ""},
+		{MacOS, `du --max-depth=1 .`, `du -d 1 .`, ""},
+		{MacOS, `git diff | xclip -selection clipboard`, `git diff | pbcopy`, ""},
+		{MacOS, `xclip -sel c -o | wc -l`, `pbpaste | wc -l`, ""},
+		{MacOS, `grep -P '\d+' f`, `grep -P '\d+' f`, "BSD grep has no -P"},
+		{MacOS, `free -h`, `free -h`, "vm_stat"},
+
+		// bash to fish
+		{Fish, `export EDITOR=vim`, `set -gx EDITOR vim`, ""},
+		{Fish, `unset EDITOR`, `set -e EDITOR`, ""},
+		{Fish, `FOO=1 make`, `env FOO=1 make`, ""},
+		{Fish, `echo $?`, `echo $status`, ""},
+		{Fish, "echo `date`", `echo (date)`, ""},
+		{Fish, `cd $(git rev-parse --show-toplevel)`, `cd (git rev-parse --show-toplevel)`, ""},
+		{Fish, `source ~/.bashrc`, `source ~/.config/fish/config.fish`, ""},
+		{Fish, `if [[ -f x ]]; then echo y; fi`, `if [[ -f x ]]; then echo y; fi`, "[[ ]]"},
+
+		// bash to PowerShell
+		{PowerShell, `ls`, `Get-ChildItem`, ""},
+		{PowerShell, `ls -la`, `Get-ChildItem -Force`, ""},
+		{PowerShell, `ls -la dir`, `Get-ChildItem -Force dir`, ""},
+		{PowerShell, `ls -l dir`, `Get-ChildItem dir`, ""},
+		{PowerShell, `ls -R src`, `Get-ChildItem -Recurse src`, ""},
+		{PowerShell, `ls -lt dir`, `Get-ChildItem dir`, "ls -t"},
+		{PowerShell, `rm -rf build`, `Remove-Item -Recurse -Force build`, ""},
+		{PowerShell, `rm -f out.log`, `Remove-Item out.log -ErrorAction SilentlyContinue`, ""},
+		{PowerShell, `rm out.log`, `Remove-Item out.log`, ""},
+		{PowerShell, `cat f.txt | grep -i error`, `Get-Content f.txt | Select-String -Pattern error`, ""},
+		{PowerShell, `grep TODO main.go`, `Select-String -Pattern TODO -Path main.go -CaseSensitive`, ""},
+		{PowerShell, `echo $PATH`, `Write-Output $env:PATH`, ""},
+		{PowerShell, `echo $HOME`, `Write-Output $HOME`, ""},
+		{PowerShell, `export FOO=bar`, `$env:FOO = "bar"`, ""},
+		{PowerShell, `head -n 5 f.txt`, `Get-Content f.txt -TotalCount 5`, ""},
+		{PowerShell, `ps aux | tail -3`, `Get-Process | Select-Object -Last 3`, ""},
+		{PowerShell, `make && make test`, `make && make test`, "no PowerShell rule for make"},
+		{PowerShell, `sudo rm x`, `sudo rm x`, "sudo"},
+	}
+
+	for _, tt := range tests {
+		result, err := Translate(tt.command, tt.target)
+		if err != nil {
+			t.Fatalf("Translate(%q, %s): %v", tt.command, tt.target, err)
+		}
+		if result.Command != tt.want {
+			t.Errorf("Translate(%q, %s) = %q, want %q", tt.command, tt.target, result.Command, tt.want)
+		}
+
+		gaps := strings.Join(result.Gaps, "\n")
+		switch {
+		case tt.gap == "" && gaps != "":
+			t.Errorf("Translate(%q, %s) reported gaps %q", tt.command, tt.target, gaps)
+		case tt.gap != "" && !strings.Contains(gaps, tt.gap):
+			t.Errorf("Translate(%q, %s) gaps = %q, want one mentioning %q", tt.command, tt.target, gaps, tt.gap)
+		}
+	}
+}
+
+func TestStatFormatRoundTrip(t *testing.T) {
+	for bsd, gnu := range statFormats {
+		if got := convertStatFormat(bsd, false); got != gnu {
+			t.Errorf("convertStatFormat(%q) = %q, want %q", bsd, got, gnu)
+		}
+		if got := convertStatFormat(gnu, true); got != bsd {
+			t.Errorf("convertStatFormat(%q, reverse) = %q, want %q", gnu, got, bsd)
+		}
+	}
+}
+
+func TestTranslateUnknownTarget(t *testing.T) {
+	if _, err := Translate("ls", "plan9"); err == nil {
+		t.Error("Translate accepted an unknown target")
+	}
+}
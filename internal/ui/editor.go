@@ -0,0 +1,613 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/utils"
+
+	"github.com/fatih/color"
+)
+
+// ErrInterrupted is returned when Ctrl+C abandons the current line
+var ErrInterrupted = errors.New("interrupted")
+
+// Keys marking the start and end of a bracketed paste
+const (
+	keyPasteStart = "paste-start"
+	keyPasteEnd   = "paste-end"
+)
+
+// Escape sequences turning the terminal's bracketed paste mode on and off
+const (
+	bracketedPasteOn  = "\033[?2004h"
+	bracketedPasteOff = "\033[?2004l"
+)
+
+// historyLimit caps the number of lines kept for up/down navigation
+const historyLimit = 1000
+
+// EditResult tells the editor how a key binding changed the line
+type EditResult struct {
+	Line    string
+	Cursor  int    // rune offset; -1 puts the cursor at the end
+	Accept  bool   // submit Line as if Enter was pressed
+	Message string // shown under the prompt
+}
+
+// Binding handles a key press given the current line and cursor. The
+// context is cancelled when Ctrl+C or Esc is pressed while it runs.
+type Binding func(ctx context.Context, line string, cursor int) EditResult
+
+// boundKey is a binding and the status shown while it runs
+type boundKey struct {
+	fn     Binding
+	status string
+}
+
+// snapshot is one entry in the undo stack
+type snapshot struct {
+	line   []rune
+	cursor int
+}
+
+// LineEditor reads a line of input with history, tab completion, undo and
+// custom key bindings
+type LineEditor struct {
+	// Complete returns candidates for the current line; Tab cycles them
+	Complete func(line string) []string
+
+	history  []string
+	bindings map[string]boundKey
+	reader   *bufio.Reader
+
+	// State of the line being edited
+	prompt      string
+	line        []rune
+	cursor      int
+	undo        []snapshot
+	lastInsert  bool
+	historyPos  int
+	draft       string
+	completions []string
+	completeIdx int
+	completeSrc string
+	pasting     bool
+}
+
+// NewLineEditor creates an editor with the default key bindings
+func NewLineEditor() *LineEditor {
+	return &LineEditor{
+		bindings: make(map[string]boundKey),
+		reader:   bufio.NewReader(os.Stdin),
+	}
+}
+
+// Bind attaches fn to a key name as returned by ReadKey, such as "ctrl+g" or
+// "alt+f". Status, if set, is shown while fn runs.
+func (e *LineEditor) Bind(key string, status string, fn Binding) {
+	e.bindings[key] = boundKey{fn: fn, status: status}
+}
+
+// Unbind removes a custom binding, restoring the key's default behaviour
+func (e *LineEditor) Unbind(key string) {
+	delete(e.bindings, key)
+}
+
+// AppendHistory adds a line to the history used by up and down
+func (e *LineEditor) AppendHistory(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > historyLimit {
+		e.history = e.history[len(e.history)-historyLimit:]
+	}
+}
+
+// ReadHistory loads one history entry per line
+func (e *LineEditor) ReadHistory(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		e.AppendHistory(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// WriteHistory saves the history, one entry per line
+func (e *LineEditor) WriteHistory(w io.Writer) error {
+	for _, line := range e.history {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prompt reads a line starting from initial text. When stdin is not a
+// terminal it falls back to reading a plain line.
+func (e *LineEditor) Prompt(prompt string, initial string) (string, error) {
+	e.prompt = prompt
+	e.line = []rune(initial)
+	e.cursor = len(e.line)
+	e.undo = nil
+	e.lastInsert = false
+	e.historyPos = len(e.history)
+	e.draft = ""
+	e.pasting = false
+	e.resetCompletion()
+
+	var result string
+	err := withRawMode(func() error {
+		var err error
+		result, err = e.edit()
+		return err
+	})
+
+	if err != nil && !errors.Is(err, ErrInterrupted) && !errors.Is(err, io.EOF) {
+		// No terminal to put in raw mode: read a plain line instead
+		fmt.Print(prompt)
+		text, readErr := e.reader.ReadString('\n')
+		if readErr != nil && text == "" {
+			return "", readErr
+		}
+		return strings.TrimRight(text, "\r\n"), nil
+	}
+
+	return result, err
+}
+
+// edit runs the key loop until the line is accepted or abandoned. The
+// terminal marks pasted text, so a newline in a paste doesn't run the line.
+func (e *LineEditor) edit() (string, error) {
+	fmt.Print(bracketedPasteOn)
+	defer fmt.Print(bracketedPasteOff)
+	e.render()
+
+	buf := make([]byte, 256)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", err
+		}
+
+		for _, key := range splitKeys(buf[:n]) {
+			done, err := e.handleKey(key)
+			if err != nil {
+				fmt.Print("\r\n")
+				return "", err
+			}
+			if done {
+				fmt.Print("\r\n")
+				return string(e.line), nil
+			}
+		}
+		e.render()
+	}
+}
+
+// handleKey applies one key press and reports whether the line is finished
+func (e *LineEditor) handleKey(key string) (bool, error) {
+	if key == keyPasteStart || key == keyPasteEnd || (e.pasting && key != KeyCtrlC) {
+		e.paste(key)
+		return false, nil
+	}
+	if key != "\t" {
+		e.resetCompletion()
+	}
+	if key != "" && !isPrintableKey(key) {
+		e.lastInsert = false
+	}
+
+	if bound, ok := e.bindings[key]; ok {
+		return e.runBinding(bound), nil
+	}
+
+	switch key {
+	case KeyEnter:
+		return true, nil
+	case KeyCtrlC:
+		e.line = nil
+		return false, ErrInterrupted
+	case "ctrl+d":
+		if len(e.line) == 0 {
+			return false, io.EOF
+		}
+		e.deleteAt(e.cursor)
+	case "backspace", "ctrl+h":
+		if e.cursor > 0 {
+			e.save()
+			e.line = append(e.line[:e.cursor-1], e.line[e.cursor:]...)
+			e.cursor--
+		}
+	case "delete":
+		e.deleteAt(e.cursor)
+	case KeyLeft, "ctrl+b":
+		if e.cursor > 0 {
+			e.cursor--
+		}
+	case KeyRight, "ctrl+f":
+		if e.cursor < len(e.line) {
+			e.cursor++
+		}
+	case "home", "ctrl+a":
+		e.cursor = 0
+	case "end", "ctrl+e":
+		e.cursor = len(e.line)
+	case "alt+b", "ctrl+left":
+		e.cursor = e.wordStart(e.cursor)
+	case "alt+f", "ctrl+right":
+		e.cursor = e.wordEnd(e.cursor)
+	case "ctrl+u":
+		e.save()
+		e.line = append([]rune{}, e.line[e.cursor:]...)
+		e.cursor = 0
+	case "ctrl+k":
+		e.save()
+		e.line = e.line[:e.cursor]
+	case "ctrl+w":
+		start := e.wordStart(e.cursor)
+		e.save()
+		e.line = append(e.line[:start], e.line[e.cursor:]...)
+		e.cursor = start
+	case "ctrl+_", "ctrl+z":
+		e.restore()
+	case "ctrl+l":
+		fmt.Print("\033[H\033[2J")
+	case KeyUp, "ctrl+p":
+		e.moveHistory(-1)
+	case KeyDown, "ctrl+n":
+		e.moveHistory(1)
+	case "\t":
+		e.complete()
+	default:
+		if isPrintableKey(key) {
+			// Typing a run of characters is undone as one step
+			if !e.lastInsert {
+				e.save()
+			}
+			e.lastInsert = true
+			e.insert([]rune(key))
+		}
+	}
+
+	return false, nil
+}
+
+// paste inserts pasted text as it is, newlines and tabs included, as one
+// undo step. Other control keys in a paste are dropped.
+func (e *LineEditor) paste(key string) {
+	switch key {
+	case keyPasteStart:
+		e.pasting = true
+		e.save()
+	case keyPasteEnd:
+		e.pasting = false
+	case KeyEnter:
+		e.insert([]rune{'\n'})
+	case "\t":
+		e.insert([]rune{'\t'})
+	default:
+		if isPrintableKey(key) {
+			e.insert([]rune(key))
+		}
+	}
+	e.lastInsert = false
+}
+
+// runBinding calls a custom binding, recording an undo step if it changes
+// the line
+func (e *LineEditor) runBinding(bound boundKey) bool {
+	if bound.status != "" {
+		fmt.Print("  " + color.New(color.FgHiBlack).Sprint(bound.status))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan EditResult, 1)
+	line, cursor := string(e.line), e.cursor
+	go func() {
+		done <- bound.fn(ctx, line, cursor)
+	}()
+	result := awaitBinding(done, cancel)
+
+	if result.Line != string(e.line) {
+		e.save()
+		e.line = []rune(result.Line)
+	}
+	e.cursor = result.Cursor
+	if e.cursor < 0 || e.cursor > len(e.line) {
+		e.cursor = len(e.line)
+	}
+
+	if result.Message != "" {
+		e.render()
+		fmt.Print("\r\n" + strings.ReplaceAll(result.Message, "\n", "\r\n") + "\r\n")
+	}
+
+	return result.Accept
+}
+
+// keyFeed passes the keys read while a binding runs to ReadKey, so the
+// binding can ask for a confirmation
+var keyFeed atomic.Pointer[chan string]
+
+// awaitBinding waits for the result of a binding running in the
+// background. Meanwhile Ctrl+C or Esc cancels it, unless the binding is
+// waiting in ReadKey, which gets the key instead.
+func awaitBinding(done <-chan EditResult, cancel context.CancelFunc) EditResult {
+	// Reads give up after a tenth of a second so the result is noticed, and
+	// newlines the binding prints start a new line again
+	if err := stty("min", "0", "time", "1", "opost"); err != nil {
+		return <-done
+	}
+	defer stty("min", "1", "time", "0", "-opost")
+
+	feed := make(chan string)
+	keyFeed.Store(&feed)
+	defer keyFeed.Store(nil)
+
+	buf := make([]byte, 64)
+	for {
+		select {
+		case result := <-done:
+			return result
+		default:
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return <-done
+		}
+		for _, key := range splitKeys(buf[:n]) {
+			select {
+			case feed <- key:
+			default:
+				if key == KeyCtrlC || key == KeyEscape {
+					cancel()
+				}
+			}
+		}
+	}
+}
+
+// save pushes the current line onto the undo stack
+func (e *LineEditor) save() {
+	e.undo = append(e.undo, snapshot{line: append([]rune{}, e.line...), cursor: e.cursor})
+}
+
+// restore pops the undo stack
+func (e *LineEditor) restore() {
+	if len(e.undo) == 0 {
+		return
+	}
+	last := e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.line, e.cursor = last.line, last.cursor
+}
+
+// insert adds runes at the cursor
+func (e *LineEditor) insert(runes []rune) {
+	line := make([]rune, 0, len(e.line)+len(runes))
+	line = append(line, e.line[:e.cursor]...)
+	line = append(line, runes...)
+	e.line = append(line, e.line[e.cursor:]...)
+	e.cursor += len(runes)
+}
+
+// deleteAt removes the rune under pos
+func (e *LineEditor) deleteAt(pos int) {
+	if pos < len(e.line) {
+		e.save()
+		e.line = append(e.line[:pos], e.line[pos+1:]...)
+	}
+}
+
+// wordStart finds the start of the word before pos
+func (e *LineEditor) wordStart(pos int) int {
+	for pos > 0 && unicode.IsSpace(e.line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(e.line[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEnd finds the end of the word after pos
+func (e *LineEditor) wordEnd(pos int) int {
+	for pos < len(e.line) && unicode.IsSpace(e.line[pos]) {
+		pos++
+	}
+	for pos < len(e.line) && !unicode.IsSpace(e.line[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// moveHistory steps through history, keeping the unsent line as a draft
+func (e *LineEditor) moveHistory(delta int) {
+	pos := e.historyPos + delta
+	if pos < 0 || pos > len(e.history) {
+		return
+	}
+	if e.historyPos == len(e.history) {
+		e.draft = string(e.line)
+	}
+
+	e.historyPos = pos
+	if pos == len(e.history) {
+		e.line = []rune(e.draft)
+	} else {
+		e.line = []rune(e.history[pos])
+	}
+	e.cursor = len(e.line)
+}
+
+// complete cycles through the completer's candidates
+func (e *LineEditor) complete() {
+	if e.Complete == nil {
+		return
+	}
+
+	if e.completions == nil {
+		e.completeSrc = string(e.line)
+		e.completions = e.Complete(e.completeSrc)
+		e.completeIdx = -1
+		if len(e.completions) == 0 {
+			e.completions = nil
+			fmt.Print("\a")
+			return
+		}
+		e.save()
+	}
+
+	// Cycle through the candidates, then back to what was typed
+	e.completeIdx++
+	if e.completeIdx == len(e.completions) {
+		e.completeIdx = -1
+		e.line = []rune(e.completeSrc)
+	} else {
+		e.line = []rune(e.completions[e.completeIdx])
+	}
+	e.cursor = len(e.line)
+}
+
+// resetCompletion ends a Tab cycle
+func (e *LineEditor) resetCompletion() {
+	e.completions = nil
+	e.completeIdx = -1
+}
+
+// render redraws the prompt and line, scrolling horizontally so the cursor
+// stays visible
+func (e *LineEditor) render() {
+	promptWidth := utf8.RuneCountInString(utils.StripAnsi(e.prompt))
+	room := utils.GetTerminalWidth() - promptWidth - 1
+	if room < 10 {
+		room = 10
+	}
+
+	start := 0
+	if e.cursor > room {
+		start = e.cursor - room
+	}
+	end := len(e.line)
+	if end-start > room {
+		end = start + room
+	}
+
+	// Pasted newlines and tabs are shown one column wide
+	visible := strings.NewReplacer("\n", "↵", "\t", " ").Replace(string(e.line[start:end]))
+	fmt.Print("\r\033[K" + e.prompt + visible)
+	if back := end - e.cursor; back > 0 {
+		fmt.Printf("\033[%dD", back)
+	}
+}
+
+// isPrintableKey reports whether a key name is text to insert
+func isPrintableKey(key string) bool {
+	r, _ := utf8.DecodeRuneInString(key)
+	return key != "" && key != "\t" && utf8.RuneCountInString(key) == 1 && unicode.IsPrint(r)
+}
+
+// splitKeys breaks a chunk of raw input, which may hold several keys or a
+// paste, into key names
+func splitKeys(b []byte) []string {
+	var keys []string
+
+	for len(b) > 0 {
+		switch {
+		case b[0] == 27 && len(b) >= 3 && (b[1] == '[' || b[1] == 'O'):
+			// CSI/SS3 sequence: parameters end at a byte in 0x40-0x7e
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end == len(b) {
+				end--
+			}
+			keys = append(keys, escapeKey(string(b[2:end]), b[end]))
+			b = b[end+1:]
+		case b[0] == 27 && len(b) >= 2:
+			r, size := utf8.DecodeRune(b[1:])
+			keys = append(keys, "alt+"+string(r))
+			b = b[1+size:]
+		case b[0] == 27:
+			keys = append(keys, KeyEscape)
+			b = b[1:]
+		case b[0] == '\r' && len(b) >= 2 && b[1] == '\n':
+			keys = append(keys, KeyEnter)
+			b = b[2:]
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, KeyEnter)
+			b = b[1:]
+		case b[0] == '\t':
+			keys = append(keys, "\t")
+			b = b[1:]
+		case b[0] == 127:
+			keys = append(keys, "backspace")
+			b = b[1:]
+		case b[0] == 31:
+			keys = append(keys, "ctrl+_")
+			b = b[1:]
+		case b[0] < 32:
+			keys = append(keys, decodeKey(b[:1]))
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, string(r))
+			b = b[size:]
+		}
+	}
+
+	return keys
+}
+
+// escapeKey names a CSI/SS3 sequence from its parameters and final byte
+func escapeKey(params string, final byte) string {
+	switch final {
+	case 'A':
+		return KeyUp
+	case 'B':
+		return KeyDown
+	case 'C':
+		if strings.HasSuffix(params, ";5") {
+			return "ctrl+right"
+		}
+		return KeyRight
+	case 'D':
+		if strings.HasSuffix(params, ";5") {
+			return "ctrl+left"
+		}
+		return KeyLeft
+	case 'H':
+		return "home"
+	case 'F':
+		return "end"
+	case '~':
+		switch params {
+		case "1", "7":
+			return "home"
+		case "4", "8":
+			return "end"
+		case "3":
+			return "delete"
+		case "200":
+			return keyPasteStart
+		case "201":
+			return keyPasteEnd
+		}
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/utils"
+
+	"github.com/fatih/color"
+)
+
+// Key names returned by ReadKey for non-printable keys
+const (
+	KeyUp     = "up"
+	KeyDown   = "down"
+	KeyLeft   = "left"
+	KeyRight  = "right"
+	KeyEnter  = "enter"
+	KeyEscape = "esc"
+	KeyCtrlC  = "ctrl+c"
+)
+
+// lineBreaks are shown as spaces so a picker item stays on one row
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// ErrCancelled is returned when the user dismisses a picker
+var ErrCancelled = errors.New("cancelled")
+
+// PickerItem is a single choice shown in a picker
+type PickerItem struct {
+	Title  string
+	Detail string
+	Note   string
+}
+
+// stty changes settings of the terminal on stdin
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// withRawMode runs fn with the terminal in raw mode, restoring it afterwards
+func withRawMode(fn func() error) error {
+	saved := exec.Command("stty", "-g")
+	saved.Stdin = os.Stdin
+	state, err := saved.Output()
+	if err != nil {
+		return err
+	}
+
+	if err := stty("raw", "-echo"); err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = stty(strings.TrimSpace(string(state)))
+	}()
+
+	return fn()
+}
+
+// ReadKey waits for a single key press and returns its name. Printable keys
+// are returned as themselves, Alt+<key> as "alt+<key>". While a key binding
+// runs, the editor reads the keys and passes them on.
+func ReadKey() (string, error) {
+	if feed := keyFeed.Load(); feed != nil {
+		return <-*feed, nil
+	}
+
+	var key string
+
+	err := withRawMode(func() error {
+		buf := make([]byte, 8)
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		key = decodeKey(buf[:n])
+		return nil
+	})
+
+	return key, err
+}
+
+// decodeKey maps raw terminal input to a key name
+func decodeKey(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	switch {
+	case len(b) >= 3 && b[0] == 27 && (b[1] == '[' || b[1] == 'O'):
+		switch b[2] {
+		case 'A':
+			return KeyUp
+		case 'B':
+			return KeyDown
+		case 'C':
+			return KeyRight
+		case 'D':
+			return KeyLeft
+		}
+		return KeyEscape
+	case len(b) == 2 && b[0] == 27:
+		return "alt+" + string(b[1])
+	case b[0] == 27:
+		return KeyEscape
+	case b[0] == '\r' || b[0] == '\n':
+		return KeyEnter
+	case b[0] == 3:
+		return KeyCtrlC
+	case b[0] == '\t':
+		return "\t"
+	case b[0] < 32:
+		return fmt.Sprintf("ctrl+%c", b[0]+'a'-1)
+	}
+
+	return string(b)
+}
+
+// Pick shows an arrow-key menu of items and returns the chosen index
+func Pick(title string, items []PickerItem) (int, error) {
+	if len(items) == 0 {
+		return -1, errors.New("nothing to pick from")
+	}
+
+	titleColor := color.New(color.FgMagenta, color.Bold).SprintFunc()
+	selectedColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	itemColor := color.New(color.FgCyan).SprintFunc()
+	hintColor := color.New(color.FgHiBlack).SprintFunc()
+	noteColor := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Println(titleColor(title), hintColor("(↑/↓ to move, enter to select, esc to cancel)"))
+
+	selected := 0
+	lines := 0
+
+	// Every item must fit on one row, or moving back up over it misses the
+	// rows it wrapped onto. The last column is left free for the cursor.
+	width := utils.GetTerminalWidth() - 1
+
+	render := func() {
+		// Move back up over the previous render
+		if lines > 0 {
+			fmt.Printf("\033[%dA", lines)
+		}
+		lines = 0
+
+		for i, item := range items {
+			number := fmt.Sprintf("%d. ", i+1)
+			room := width - 2 - len(number)
+			note := item.Note
+			if note != "" {
+				note = fitWidth(note, room/2)
+				room -= utf8.RuneCountInString(note) + 1
+			}
+			text := fitWidth(item.Title, room)
+
+			marker := "  "
+			title := itemColor(text)
+			if i == selected {
+				marker = selectedColor("❯ ")
+				title = selectedColor(text)
+			}
+
+			fmt.Printf("\r\033[K%s%s%s", marker, number, title)
+			if note != "" {
+				fmt.Print(" " + noteColor(note))
+			}
+			fmt.Print("\r\n")
+			lines++
+
+			if item.Detail != "" {
+				fmt.Printf("\r\033[K     %s\r\n", hintColor(fitWidth(item.Detail, width-5)))
+				lines++
+			}
+		}
+	}
+
+	err := withRawMode(func() error {
+		render()
+		for {
+			buf := make([]byte, 8)
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return err
+			}
+
+			switch key := decodeKey(buf[:n]); key {
+			case KeyUp, "k":
+				selected = (selected - 1 + len(items)) % len(items)
+			case KeyDown, "j", "\t":
+				selected = (selected + 1) % len(items)
+			case KeyEnter:
+				return nil
+			case KeyEscape, KeyCtrlC, "q":
+				return ErrCancelled
+			default:
+				if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+					if index := int(key[0] - '1'); index < len(items) {
+						selected = index
+						render()
+						return nil
+					}
+				}
+			}
+			render()
+		}
+	})
+
+	if err != nil {
+		return -1, err
+	}
+
+	return selected, nil
+}
+
+// fitWidth shortens s to at most width characters on a single line,
+// marking a cut with …
+func fitWidth(s string, width int) string {
+	runes := []rune(lineBreaks.Replace(s))
+	if len(runes) <= width {
+		return string(runes)
+	}
+	if width < 1 {
+		return ""
+	}
+	return string(runes[:width-1]) + "…"
+}
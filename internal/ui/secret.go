@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ReadSecret prompts for a line of input without echoing it
+func ReadSecret(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	noEcho := exec.Command("stty", "-echo")
+	noEcho.Stdin = os.Stdin
+	if err := noEcho.Run(); err == nil {
+		defer func() {
+			echo := exec.Command("stty", "echo")
+			echo.Stdin = os.Stdin
+			_ = echo.Run()
+			fmt.Println()
+		}()
+	}
+
+	text, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && text == "" {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Config holds the user settings stored in ~/.goterm.json
+type Config struct {
+	GeminiAPIKey      string `json:"gemini_apiKey"`
+	CredentialCommand string `json:"credential_command,omitempty"`
+	Model             string `json:"model,omitempty"`
+	DefaultTimeout    int    `json:"default_timeout,omitempty"`
+	HpCandidates      int    `json:"hp_candidates,omitempty"`
+
+	Context   ContextConfig   `json:"context"`
+	Redaction RedactionConfig `json:"redaction"`
+	Cache     CacheConfig     `json:"cache"`
+	Usage     UsageConfig     `json:"usage"`
+	AutoFix   AutoFixConfig   `json:"autofix"`
+	Agent     AgentConfig     `json:"agent"`
+	Capture   CaptureConfig   `json:"capture"`
+	Commit    CommitConfig    `json:"commit"`
+	ManPages  ManPagesConfig  `json:"manpages"`
+	Prompts   PromptsConfig   `json:"prompts"`
+	Chat      ChatConfig      `json:"chat"`
+	Clipboard ClipboardConfig `json:"clipboard"`
+}
+
+// ClipboardConfig controls the clipboard. Backend is auto, which picks one
+// for the session, or one of pbcopy, windows, wayland, xclip, xsel, osc52
+// and tmux.
+type ClipboardConfig struct {
+	Backend string `json:"backend"`
+}
+
+// ChatConfig controls chat. AttachBudget caps the bytes of files and
+// command output attached to one message with @-references.
+type ChatConfig struct {
+	AttachBudget int `json:"attach_budget"`
+}
+
+// PromptsConfig controls the prompt templates. Dir replaces
+// ~/.goterm/prompts, e.g. with a directory a team keeps in git, and
+// Commands are the user-defined AI commands.
+type PromptsConfig struct {
+	Dir      string               `json:"dir"`
+	Commands map[string]AICommand `json:"commands,omitempty"`
+}
+
+// AICommand is a user-defined AI command whose prompt is the template of
+// the same name. Output is text, copy (text, also copied to the
+// clipboard) or command (a suggested command to review and run).
+type AICommand struct {
+	Description string `json:"description,omitempty"`
+	Output      string `json:"output"`
+}
+
+// ManPagesConfig controls how he uses local man pages and --help output:
+// grounding the AI's answer, up to Budget bytes per question, and the
+// flag-by-flag Breakdown of command lines
+type ManPagesConfig struct {
+	Enabled   bool `json:"enabled"`
+	Budget    int  `json:"budget"`
+	Breakdown bool `json:"breakdown"`
+}
+
+// CommitConfig controls gcm. Style is conventional, gitmoji or plain;
+// staged diffs larger than DiffBudget bytes are summarized per file first.
+type CommitConfig struct {
+	Style      string `json:"style"`
+	DiffBudget int    `json:"diff_budget"`
+}
+
+// CaptureConfig controls the copy of each command's output kept for hx.
+// It is off by default, as a captured command writes to a pipe rather than
+// the terminal. Programs listed in Passthrough keep the terminal to
+// themselves and are never captured.
+type CaptureConfig struct {
+	Enabled     bool     `json:"enabled"`
+	Limit       int      `json:"limit"`
+	Passthrough []string `json:"passthrough"`
+}
+
+// AgentConfig controls the multi-step agent
+type AgentConfig struct {
+	MaxSteps            int  `json:"max_steps"`
+	AutoApproveReadOnly bool `json:"auto_approve_read_only"`
+	OutputLimit         int  `json:"output_limit"`
+	StepTimeout         int  `json:"step_timeout"` // seconds a step may run; 0 for no limit
+}
+
+// AutoFixConfig controls the fix offered after a command fails
+type AutoFixConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// UsageConfig controls token accounting and the daily soft limits. A limit
+// of 0 disables it.
+type UsageConfig struct {
+	Enabled         bool             `json:"enabled"`
+	DailyTokenLimit int              `json:"daily_token_limit"`
+	DailyCostLimit  float64          `json:"daily_cost_limit"`
+	Prices          map[string]Price `json:"prices,omitempty"`
+}
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// CacheConfig controls the on-disk AI response cache
+type CacheConfig struct {
+	Enabled   bool `json:"enabled"`
+	TTLHours  int  `json:"ttl_hours"`
+	MaxSizeKB int  `json:"max_size_kb"`
+}
+
+// RedactionConfig controls how secrets are stripped from AI requests
+type RedactionConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Entropy  bool     `json:"entropy"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// ContextConfig toggles the pieces of environment context sent with AI prompts
+type ContextConfig struct {
+	Enabled         bool `json:"enabled"`
+	OS              bool `json:"os"`
+	Arch            bool `json:"arch"`
+	Shell           bool `json:"shell"`
+	PackageManagers bool `json:"package_managers"`
+	ToolVersions    bool `json:"tool_versions"`
+	Directory       bool `json:"directory"`
+	Git             bool `json:"git"`
+	History         bool `json:"history"`
+	HistoryEntries  int  `json:"history_entries"`
+	Budget          int  `json:"budget"`
+}
+
+var (
+	current *Config
+	once    sync.Once
+	mu      sync.Mutex
+)
+
+// defaults returns a config populated with the built-in settings
+func defaults() *Config {
+	return &Config{
+		Model:          "gemini-1.5-flash",
+		DefaultTimeout: 30,
+		HpCandidates:   1,
+		Context: ContextConfig{
+			Enabled:         true,
+			OS:              true,
+			Arch:            true,
+			Shell:           true,
+			PackageManagers: true,
+			ToolVersions:    true,
+			Directory:       true,
+			Git:             true,
+			History:         true,
+			HistoryEntries:  5,
+			Budget:          1500,
+		},
+		Redaction: RedactionConfig{
+			Enabled: true,
+			Entropy: true,
+		},
+		Cache: CacheConfig{
+			Enabled:   true,
+			TTLHours:  24 * 7,
+			MaxSizeKB: 5 * 1024,
+		},
+		Agent: AgentConfig{
+			MaxSteps:            10,
+			AutoApproveReadOnly: false,
+			OutputLimit:         8000,
+			StepTimeout:         300,
+		},
+		ManPages: ManPagesConfig{
+			Enabled:   true,
+			Budget:    4000,
+			Breakdown: true,
+		},
+		Chat: ChatConfig{
+			AttachBudget: 24000,
+		},
+		Clipboard: ClipboardConfig{
+			Backend: "auto",
+		},
+		Commit: CommitConfig{
+			Style:      "conventional",
+			DiffBudget: 12000,
+		},
+		Capture: CaptureConfig{
+			Enabled: false,
+			Limit:   16000,
+			Passthrough: []string{
+				"vi", "vim", "nvim", "nano", "emacs", "less", "more", "man",
+				"top", "htop", "btop", "watch", "ssh", "tmux", "screen", "fzf",
+			},
+		},
+		Usage: UsageConfig{
+			Enabled: true,
+			Prices: map[string]Price{
+				"gemini-1.5-flash": {Input: 0.075, Output: 0.30},
+				"gemini-1.5-pro":   {Input: 1.25, Output: 5.00},
+				"gemini-2.0-flash": {Input: 0.10, Output: 0.40},
+			},
+		},
+	}
+}
+
+// GetConfigPath returns the path to the config file
+func GetConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".goterm.json"
+	}
+	return filepath.Join(homeDir, ".goterm.json")
+}
+
+// GetConfigDir returns the directory holding GO-TERM's data files,
+// creating it if needed
+func GetConfigDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+
+	dir := filepath.Join(homeDir, ".goterm")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		_ = os.MkdirAll(dir, 0700)
+	}
+	return dir
+}
+
+// GetConfig returns the loaded configuration, reading it on first use
+func GetConfig() *Config {
+	once.Do(func() {
+		current = load()
+	})
+	return current
+}
+
+// load reads the config file, falling back to defaults for missing values
+func load() *Config {
+	cfg := defaults()
+
+	data, err := os.ReadFile(GetConfigPath())
+	if err != nil {
+		return cfg
+	}
+	_ = json.Unmarshal(data, cfg)
+
+	def := defaults()
+	if cfg.Model == "" {
+		cfg.Model = def.Model
+	}
+	if cfg.DefaultTimeout <= 0 {
+		cfg.DefaultTimeout = def.DefaultTimeout
+	}
+	if cfg.HpCandidates <= 0 {
+		cfg.HpCandidates = def.HpCandidates
+	}
+
+	return cfg
+}
+
+// Save writes the current configuration back to the config file
+func Save() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := json.MarshalIndent(GetConfig(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(GetConfigPath(), data, 0600)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keyAliases maps friendly setting names onto their JSON paths
+var keyAliases = map[string]string{
+	"ai.key":        "gemini_apiKey",
+	"auth.command":  "credential_command",
+	"ai.model":      "model",
+	"ai.timeout":    "default_timeout",
+	"hp.candidates": "hp_candidates",
+}
+
+// secretKeys are masked when displayed
+var secretKeys = map[string]bool{
+	"gemini_apiKey": true,
+}
+
+// resolveKey walks a dotted key such as "context.git" to the matching field
+func resolveKey(key string) (reflect.Value, string, error) {
+	if alias, ok := keyAliases[key]; ok {
+		key = alias
+	}
+
+	value := reflect.ValueOf(GetConfig()).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, "", fmt.Errorf("unknown config key: %s", key)
+		}
+
+		found := false
+		for i := 0; i < value.NumField(); i++ {
+			if jsonName(value.Type().Field(i)) == name {
+				value = value.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, "", fmt.Errorf("unknown config key: %s", key)
+		}
+	}
+
+	if value.Kind() == reflect.Struct {
+		return reflect.Value{}, "", fmt.Errorf("%s is a section, not a setting", key)
+	}
+
+	return value, key, nil
+}
+
+// jsonName returns the JSON name of a struct field
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+// Get returns the value of a setting as a string
+func Get(key string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	value, path, err := resolveKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if secretKeys[path] {
+		return mask(value.String()), nil
+	}
+	return fmt.Sprint(value.Interface()), nil
+}
+
+// Set parses and stores a setting, then saves the config file
+func Set(key, raw string) error {
+	mu.Lock()
+	value, _, err := resolveKey(key)
+	if err == nil {
+		err = assign(value, raw)
+	}
+	mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return Save()
+}
+
+// assign parses raw according to the field's type
+func assign(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", raw)
+		}
+		value.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", raw)
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("this setting cannot be changed with config set")
+	}
+	return nil
+}
+
+// Keys returns every setting with its current display value
+func Keys() map[string]string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	keys := make(map[string]string)
+	collectKeys(reflect.ValueOf(GetConfig()).Elem(), "", keys)
+	return keys
+}
+
+// collectKeys flattens a struct into dotted keys
+func collectKeys(value reflect.Value, prefix string, keys map[string]string) {
+	for i := 0; i < value.NumField(); i++ {
+		key := prefix + jsonName(value.Type().Field(i))
+		field := value.Field(i)
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			collectKeys(field, key+".", keys)
+		case secretKeys[key]:
+			keys[key] = mask(field.String())
+		case field.Kind() == reflect.Map || field.Kind() == reflect.Slice:
+			continue
+		default:
+			keys[key] = fmt.Sprint(field.Interface())
+		}
+	}
+}
+
+// SortedKeys returns the names from Keys in alphabetical order
+func SortedKeys(keys map[string]string) []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mask hides all but the last few characters of a secret
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
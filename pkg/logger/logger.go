@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github/0PrashantYadav0/GO-TERM/pkg/config"
+)
+
+var (
+	mu sync.Mutex
+)
+
+// Debug logs a message only when GOTERM_DEBUG is set
+func Debug(format string, args ...interface{}) {
+	if os.Getenv("GOTERM_DEBUG") == "" {
+		return
+	}
+	write("DEBUG", format, args...)
+}
+
+// Info logs an informational message
+func Info(format string, args ...interface{}) {
+	write("INFO", format, args...)
+}
+
+// Error logs an error message
+func Error(format string, args ...interface{}) {
+	write("ERROR", format, args...)
+}
+
+// write appends a line to the log file in the config directory
+func write(level, format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	logPath := filepath.Join(config.GetConfigDir(), "goterm.log")
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	message := fmt.Sprintf(format, args...)
+	fmt.Fprintf(file, "%s [%s] %s\n", time.Now().Format(time.RFC3339), level, message)
+}
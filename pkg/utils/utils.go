@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"os/user"
 	"regexp"
+	"runtime"
 	"strings"
 	"time"
 )
@@ -29,7 +30,7 @@ func GetUsername() string {
 
 // GetPlatform returns the current platform
 func GetPlatform() string {
-	return os.Getenv("GOOS")
+	return runtime.GOOS
 }
 
 // GetShellName returns the shell being used